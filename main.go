@@ -1,8 +1,12 @@
 package main
 
 import (
-	rp "example.com/rplat/pkg/game"
+	"flag"
+	"fmt"
+	"os"
 	"runtime"
+
+	rp "example.com/rplat/pkg/game"
 )
 
 func init() {
@@ -10,6 +14,27 @@ func init() {
 }
 
 func main() {
-	g := rp.NewGame()
+	options := rp.DefaultOptions()
+
+	flag.StringVar(&options.Scene, "scene", options.Scene, "scene to start in (main_menu, tutorial_game, random_game)")
+	flag.StringVar(&options.MapPath, "map", options.MapPath, "ttme map file to play on")
+	flag.StringVar(&options.MapsDir, "maps", options.MapsDir, "directory listed by the level select")
+	flag.Int64Var(&options.Seed, "seed", options.Seed, "random seed used to spawn stars, 0 picks a new one each round")
+	flag.IntVar(&options.ScreenWidth, "width", options.ScreenWidth, "window width, 0 keeps the one from the settings")
+	flag.IntVar(&options.ScreenHeight, "height", options.ScreenHeight, "window height, 0 keeps the one from the settings")
+	flag.BoolVar(&options.Fullscreen, "fullscreen", options.Fullscreen, "start in fullscreen mode")
+	flag.BoolVar(&options.Debug, "debug", options.Debug, "show the debug overlay and report actions no scene handles")
+	replay := flag.String("replay", "", "play back a recorded random game replay file")
+	flag.Parse()
+
+	g := rp.NewGame(options)
+
+	if *replay != "" {
+		if err := g.PlayReplay(*replay); err != nil {
+			fmt.Println("error:", err)
+			os.Exit(1)
+		}
+	}
+
 	g.Run()
 }
@@ -0,0 +1,121 @@
+package game
+
+import (
+	"fmt"
+)
+
+// Action is something the player asks for, from the input managers to the
+// scenes. Actions are written with their name in settings and replay files.
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionMoveLeft
+	ActionMoveRight
+	ActionMoveUp
+	ActionMoveDown
+	ActionJump
+	ActionStopJump
+	ActionHook
+	ActionStopHook
+	ActionReelIn
+	ActionReelOut
+	ActionDash
+	ActionPortal
+	ActionValidate
+	ActionBack
+	ActionHelp
+	ActionQuit
+	ActionPause
+	ActionClick
+	actionCount
+)
+
+var actionNames = [actionCount]string{
+	ActionNone:      "none",
+	ActionMoveLeft:  "move_left",
+	ActionMoveRight: "move_right",
+	ActionMoveUp:    "move_up",
+	ActionMoveDown:  "move_down",
+	ActionJump:      "jump",
+	ActionStopJump:  "stop_jump",
+	ActionHook:      "hook",
+	ActionStopHook:  "stop_hook",
+	ActionReelIn:    "reel_in",
+	ActionReelOut:   "reel_out",
+	ActionDash:      "dash",
+	ActionPortal:    "portal",
+	ActionValidate:  "validate",
+	ActionBack:      "back",
+	ActionHelp:      "help",
+	ActionQuit:      "quit",
+	ActionPause:     "pause",
+	ActionClick:     "click",
+}
+
+func (a Action) Valid() bool {
+	return a > ActionNone && a < actionCount
+}
+
+func (a Action) String() string {
+	if a < ActionNone || a >= actionCount {
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+
+	return actionNames[a]
+}
+
+func ParseAction(name string) (Action, error) {
+	for a, actionName := range actionNames {
+		if actionName == name && Action(a).Valid() {
+			return Action(a), nil
+		}
+	}
+
+	return ActionNone, fmt.Errorf("unknown action %s", name)
+}
+
+func (a Action) MarshalText() ([]byte, error) {
+	if !a.Valid() {
+		return nil, fmt.Errorf("invalid action %v", a)
+	}
+
+	return []byte(a.String()), nil
+}
+
+func (a *Action) UnmarshalText(text []byte) error {
+	var err error
+
+	*a, err = ParseAction(string(text))
+	return err
+}
+
+// ActionHandlers gives the code a scene runs for each action it reacts to.
+// A nil handler marks an action the scene knowingly ignores.
+type ActionHandlers map[Action]func()
+
+// Dispatch runs the handler of each action in order. It stops once a handler
+// changes the scene stack or starts a transition, the remaining actions were
+// meant for the old scene. In debug mode, actions without handler are reported.
+func (sm *SceneManager) Dispatch(actions []Action, handlers ActionHandlers) {
+	version := sm.version
+	transitionState := sm.transitionState
+
+	for _, action := range actions {
+		handler, ok := handlers[action]
+		if !ok {
+			if Debug {
+				fmt.Printf("debug: %v has no handler in scene %v\n", action, sm.CurrentSceneName())
+			}
+			continue
+		}
+
+		if handler != nil {
+			handler()
+		}
+
+		if sm.version != version || sm.transitionState != transitionState {
+			return
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package game
+
+// ActionState is the state of an input action during one simulation step.
+type ActionState struct {
+	// Held during the step
+	Down bool
+	// Went down this step
+	Pressed bool
+	// Went up this step
+	Released bool
+	// Steps the action has been held for, kept on the step it is released
+	HeldSteps int
+}
+
+// ActionStates follows the state of every action from one step to the next,
+// so edges are seen exactly once even when a frame runs several steps.
+type ActionStates map[Action]ActionState
+
+// Update records whether the action is held this step and returns its new state.
+func (states ActionStates) Update(action Action, down bool) ActionState {
+	previous := states[action]
+
+	state := ActionState{
+		Down:     down,
+		Pressed:  down && !previous.Down,
+		Released: !down && previous.Down,
+	}
+
+	if state.Pressed {
+		state.HeldSteps = 1
+	} else if down {
+		state.HeldSteps = previous.HeldSteps + 1
+	} else if state.Released {
+		state.HeldSteps = previous.HeldSteps
+	}
+
+	states[action] = state
+	return state
+}
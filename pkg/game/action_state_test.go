@@ -0,0 +1,48 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestActionStatesUpdate(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []bool
+		want  ActionState
+	}{
+		{name: "never held", steps: []bool{false, false}, want: ActionState{}},
+		{name: "pressed", steps: []bool{false, true}, want: ActionState{Down: true, Pressed: true, HeldSteps: 1}},
+		{name: "held", steps: []bool{true, true, true}, want: ActionState{Down: true, HeldSteps: 3}},
+		{name: "released", steps: []bool{true, true, false}, want: ActionState{Released: true, HeldSteps: 2}},
+		{name: "up after release", steps: []bool{true, false, false}, want: ActionState{}},
+		{name: "pressed again", steps: []bool{true, true, false, true}, want: ActionState{Down: true, Pressed: true, HeldSteps: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states := make(ActionStates)
+
+			var got ActionState
+			for _, down := range tt.steps {
+				got = states.Update(ActionJump, down)
+			}
+
+			if got != tt.want {
+				t.Errorf("state = %+v, want %+v", got, tt.want)
+			}
+			if states[ActionJump] != got {
+				t.Errorf("kept state %+v, returned %+v", states[ActionJump], got)
+			}
+		})
+	}
+}
+
+func TestActionStatesUpdateKeepsActionsApart(t *testing.T) {
+	states := make(ActionStates)
+	states.Update(ActionJump, true)
+	states.Update(ActionDash, true)
+
+	if got := states.Update(ActionJump, true); got.Pressed || got.HeldSteps != 2 {
+		t.Errorf("jump = %+v after another action was pressed, want held for 2 steps", got)
+	}
+}
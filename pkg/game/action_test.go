@@ -0,0 +1,95 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Action
+		wantErr bool
+	}{
+		{name: "jump", want: ActionJump},
+		{name: "stop_hook", want: ActionStopHook},
+		{name: "pause", want: ActionPause},
+		{name: "none", wantErr: true},
+		{name: "Jump", wantErr: true},
+		{name: "fly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAction(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAction(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAction(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActionNames(t *testing.T) {
+	for a := ActionNone + 1; a < actionCount; a++ {
+		text, err := a.MarshalText()
+		if err != nil {
+			t.Fatalf("%v has no name: %v", int(a), err)
+		}
+
+		var parsed Action
+		if err := parsed.UnmarshalText(text); err != nil || parsed != a {
+			t.Errorf("%s reads as %v, %v, want %v", text, parsed, err, a)
+		}
+	}
+
+	for _, a := range []Action{ActionNone, actionCount, -1} {
+		if _, err := a.MarshalText(); err == nil {
+			t.Errorf("invalid action %v written to text", a)
+		}
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		actions []Action
+		want    []Action
+	}{
+		{name: "in order", actions: []Action{ActionJump, ActionDash, ActionJump}, want: []Action{ActionJump, ActionDash, ActionJump}},
+		{name: "ignored and unknown actions", actions: []Action{ActionHook, ActionJump, ActionPortal}, want: []Action{ActionJump}},
+		{name: "stack change", actions: []Action{ActionJump, ActionPause, ActionDash}, want: []Action{ActionJump, ActionPause}},
+		{name: "transition", actions: []Action{ActionQuit, ActionJump}, want: []Action{ActionQuit}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := &SceneManager{}
+
+			var handled []Action
+			handle := func(action Action) func() {
+				return func() { handled = append(handled, action) }
+			}
+
+			sm.Dispatch(tt.actions, ActionHandlers{
+				ActionJump: handle(ActionJump),
+				ActionDash: handle(ActionDash),
+				ActionHook: nil,
+				ActionPause: func() {
+					handled = append(handled, ActionPause)
+					sm.version++
+				},
+				ActionQuit: func() {
+					handled = append(handled, ActionQuit)
+					sm.transitionState = transitionOut
+				},
+			})
+
+			if !reflect.DeepEqual(handled, tt.want) {
+				t.Errorf("handled %v, want %v", handled, tt.want)
+			}
+		})
+	}
+}
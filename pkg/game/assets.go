@@ -0,0 +1,190 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+type asset struct {
+	value  interface{}
+	refs   int
+	unload func()
+}
+
+// AssetManager loads assets by key (their file path), shares them between
+// scenes and unloads them once no scene uses them anymore.
+//
+// Every successful Map, Tileset, Font, Sound or Sprite call takes a reference
+// on the asset that must be given back with the matching Release method. Maps
+// can be loaded from a LoadingScene goroutine, everything else touches the GPU
+// or the audio device and must be loaded from the main thread.
+type AssetManager struct {
+	mutex  sync.Mutex
+	assets map[string]*asset
+}
+
+func NewAssetManager() *AssetManager {
+	return &AssetManager{assets: make(map[string]*asset)}
+}
+
+func (am *AssetManager) Map(path string) (sim.MapConfiguration, error) {
+	value, err := am.acquire("map:"+path, path, func() (interface{}, func(), error) {
+		mc, err := sim.NewMapConfiguration(path)
+		return mc, nil, err
+	})
+	if err != nil {
+		return sim.MapConfiguration{}, err
+	}
+
+	return value.(sim.MapConfiguration), nil
+}
+
+func (am *AssetManager) Tileset(path string, tileWidth, tileHeight int) (Tileset, error) {
+	value, err := am.acquire(tilesetKey(path, tileWidth, tileHeight), path, func() (interface{}, func(), error) {
+		ts := NewTileset(path, tileWidth, tileHeight)
+		return ts, ts.Unload, nil
+	})
+	if err != nil {
+		return Tileset{}, err
+	}
+
+	return value.(Tileset), nil
+}
+
+func (am *AssetManager) Font(path string) (rl.Font, error) {
+	value, err := am.acquire("font:"+path, path, func() (interface{}, func(), error) {
+		font := rl.LoadFont(path)
+		return font, func() { rl.UnloadFont(font) }, nil
+	})
+	if err != nil {
+		return rl.Font{}, err
+	}
+
+	return value.(rl.Font), nil
+}
+
+func (am *AssetManager) Sound(path string) (rl.Sound, error) {
+	value, err := am.acquire("sound:"+path, path, func() (interface{}, func(), error) {
+		sound := rl.LoadSound(path)
+		return sound, func() { rl.UnloadSound(sound) }, nil
+	})
+	if err != nil {
+		return rl.Sound{}, err
+	}
+
+	return value.(rl.Sound), nil
+}
+
+func (am *AssetManager) Sprite(path string) (rl.Texture2D, error) {
+	value, err := am.acquire("sprite:"+path, path, func() (interface{}, func(), error) {
+		texture := rl.LoadTexture(path)
+		return texture, func() { rl.UnloadTexture(texture) }, nil
+	})
+	if err != nil {
+		return rl.Texture2D{}, err
+	}
+
+	return value.(rl.Texture2D), nil
+}
+
+func (am *AssetManager) ReleaseMap(path string) {
+	am.release("map:" + path)
+}
+
+func (am *AssetManager) ReleaseTileset(path string, tileWidth, tileHeight int) {
+	am.release(tilesetKey(path, tileWidth, tileHeight))
+}
+
+// tilesetKey tells apart the tilesets sliced from the same image with other tile sizes.
+func tilesetKey(path string, tileWidth, tileHeight int) string {
+	return fmt.Sprintf("tileset:%v:%vx%v", path, tileWidth, tileHeight)
+}
+
+func (am *AssetManager) ReleaseFont(path string) {
+	am.release("font:" + path)
+}
+
+func (am *AssetManager) ReleaseSound(path string) {
+	am.release("sound:" + path)
+}
+
+func (am *AssetManager) ReleaseSprite(path string) {
+	am.release("sprite:" + path)
+}
+
+// Collect unloads every asset no scene holds a reference on.
+func (am *AssetManager) Collect() {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	for key, a := range am.assets {
+		if a.refs <= 0 {
+			am.unload(key, a)
+		}
+	}
+}
+
+// UnloadAll unloads every asset, whoever still uses it.
+func (am *AssetManager) UnloadAll() {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	for key, a := range am.assets {
+		am.unload(key, a)
+	}
+}
+
+func (am *AssetManager) acquire(key, path string, load func() (interface{}, func(), error)) (interface{}, error) {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	if a, ok := am.assets[key]; ok {
+		a.refs++
+		return a.value, nil
+	}
+
+	if err := CheckAsset(path); err != nil {
+		return nil, err
+	}
+
+	value, unload, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	am.assets[key] = &asset{value: value, refs: 1, unload: unload}
+	return value, nil
+}
+
+// release gives back a reference taken on an asset, it stays loaded until the
+// next Collect.
+func (am *AssetManager) release(key string) {
+	am.mutex.Lock()
+	defer am.mutex.Unlock()
+
+	if a, ok := am.assets[key]; ok && a.refs > 0 {
+		a.refs--
+	}
+}
+
+func (am *AssetManager) unload(key string, a *asset) {
+	if a.unload != nil {
+		a.unload()
+	}
+
+	delete(am.assets, key)
+}
+
+// CheckAsset reports missing asset files. raylib does not fail on those, it
+// just gives back empty assets.
+func CheckAsset(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("missing asset %s: %v", path, err)
+	}
+
+	return nil
+}
@@ -1,6 +1,8 @@
 package game
 
 import (
+	"fmt"
+
 	rl "github.com/chunqian/go-raylib/raylib"
 )
 
@@ -8,50 +10,136 @@ const ScreenWidth = 1280
 const ScreenHeight = 700
 const FPS = 120
 
-// Debug
-const Debug = true
+// StepDuration is the fixed duration of a simulation step, in seconds
+const StepDuration = 0.01
 
-var Pause = false
+// Debug shows the debug overlay
+var Debug = false
 
 type Game struct {
-	currentTime  float64
-	dt           float64
-	time         float64
-	accumulator  float64
-	inputManager InputManager
-	sm           SceneManager
+	currentTime float64
+	dt          float64
+	time        float64
+	accumulator float64
+	clock       Clock
+	sm          SceneManager
+	replay      *Replay
+	options     Options
+	settings    *Settings
 }
 
-func NewGame() Game {
+func NewGame(options Options) Game {
 	g := Game{}
 
-	g.currentTime = rl.GetTime()
-	g.dt = 0.01
-	g.inputManager = NewInputManager()
+	g.options = options
+
+	settings, err := LoadSettings()
+	if err != nil {
+		fmt.Println("error: could not load settings:", err)
+	}
+	g.settings = &settings
+
+	g.clock = RaylibClock{}
+	g.currentTime = g.clock.Now()
+	g.dt = StepDuration
 
 	return g
 }
 
 func (g *Game) Run() {
-	rl.InitWindow(ScreenWidth, ScreenHeight, "rplat")
+	// Command line options only last for this run, they are not saved
+	g.settings.SetOverrides(g.options)
+	settings := g.settings.Effective()
+
+	rl.InitWindow(int32(settings.ScreenWidth), int32(settings.ScreenHeight), "rplat")
 	defer rl.CloseWindow()
 
-	scenes := make(map[string]Scene)
-	g.sm = SceneManager{scenes: scenes, currentSceneName: "main_menu"}
-	scenes["main_menu"] = NewMainMenuSceneWrapper(&g.sm)
-	scenes["random_game"] = NewRandGameSceneWrapper(&g.sm)
-	scenes["tutorial_game"] = NewTuorialGameSceneWrapper(&g.sm)
+	// Escape opens the pause menu instead of closing the window
+	rl.SetExitKey(int32(rl.KEY_NULL))
+
+	rl.InitAudioDevice()
+	defer rl.CloseAudioDevice()
+
+	g.settings.Apply()
+
+	assets := NewAssetManager()
+	defer assets.UnloadAll()
+
+	g.sm = NewSceneManager(assets, g.settings, "main_menu")
+	g.sm.Register("main_menu", func(sm *SceneManager) Scene {
+		return NewMainMenuScene(sm)
+	})
+	g.sm.Register("random_game", func(sm *SceneManager) Scene {
+		rgs := NewRandomGameScene(sm, g.options)
+		if g.replay != nil {
+			rgs.PlayReplay(g.replay)
+		}
+		return rgs
+	})
+	g.sm.Register("tutorial_game", func(sm *SceneManager) Scene {
+		return NewTuorialGameScene(sm, g.options)
+	})
+	g.sm.RegisterRecreated("help", func(sm *SceneManager) Scene {
+		return NewHelpScene(sm)
+	})
+	g.sm.RegisterRecreated("pause", func(sm *SceneManager) Scene {
+		return NewPauseScene(sm)
+	})
+	g.sm.RegisterRecreated("results", func(sm *SceneManager) Scene {
+		return NewResultsScene(sm)
+	})
+	g.sm.RegisterRecreated("settings", func(sm *SceneManager) Scene {
+		return NewSettingsScene(sm)
+	})
+	g.sm.RegisterRecreated("level_select", func(sm *SceneManager) Scene {
+		return NewLevelSelectScene(sm, g.options)
+	})
+
+	startScene := g.options.Scene
+	if g.replay != nil {
+		startScene = "random_game"
+	}
+
+	if !isStartScene(startScene) {
+		fmt.Println("error: cannot start in scene", startScene)
+		startScene = "main_menu"
+	}
 
-	rl.SetTargetFPS(FPS)
+	g.sm.SwapScene(startScene)
 
 	for !rl.WindowShouldClose() && !g.sm.ShouldExit() {
 		g.Tick()
 	}
 }
 
+// Scenes the game can start in, the others are overlays that need a scene below
+var startScenes = []string{"main_menu", "tutorial_game", "random_game"}
+
+func isStartScene(name string) bool {
+	for _, scene := range startScenes {
+		if scene == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PlayReplay loads a replay file that will be played back in a random game as
+// soon as the game runs.
+func (g *Game) PlayReplay(path string) error {
+	replay, err := LoadReplay(path)
+	if err != nil {
+		return err
+	}
+
+	g.replay = replay
+	return nil
+}
+
 // See https://gafferongames.com/post/fix_your_timestep/
 func (g *Game) Tick() {
-	newTime := rl.GetTime()
+	newTime := g.clock.Now()
 	frameTime := newTime - g.currentTime
 	if frameTime > 0.25 {
 		frameTime = 0.25
@@ -60,6 +148,8 @@ func (g *Game) Tick() {
 	g.currentTime = newTime
 	g.accumulator += frameTime
 
+	g.sm.PollInputs()
+
 	for g.accumulator >= g.dt {
 		g.sm.UpdateInputs()
 		g.sm.HandleEvents()
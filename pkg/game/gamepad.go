@@ -0,0 +1,151 @@
+package game
+
+import (
+	"fmt"
+	"math"
+
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// Sticks and triggers under this value are considered at rest
+const GamepadDeadzone = 0.25
+
+// Distance from the player of the aim position given by the right stick
+const GamepadAimDistance = 200
+
+// raylib only handles this many gamepads
+const maxGamepads = 4
+
+// GamepadBindings gives the buttons of each action, any of them triggers it.
+type GamepadBindings map[Action][]rl.GamepadButton
+
+func DefaultGamepadBindings() map[InputContext]GamepadBindings {
+	b := make(GamepadBindings)
+
+	b[ActionJump] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_RIGHT_FACE_DOWN, rl.GAMEPAD_BUTTON_LEFT_TRIGGER_2}
+	b[ActionMoveLeft] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_LEFT_FACE_LEFT}
+	b[ActionMoveRight] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_LEFT_FACE_RIGHT}
+	b[ActionHook] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_RIGHT_TRIGGER_1}
+	b[ActionReelIn] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_LEFT_FACE_UP}
+	b[ActionReelOut] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_LEFT_FACE_DOWN}
+	b[ActionDash] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_RIGHT_TRIGGER_2, rl.GAMEPAD_BUTTON_RIGHT_FACE_LEFT}
+	b[ActionPortal] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_LEFT_TRIGGER_1}
+	b[ActionValidate] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_RIGHT_FACE_DOWN}
+	b[ActionHelp] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_MIDDLE_LEFT}
+	b[ActionQuit] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_RIGHT_FACE_RIGHT}
+	b[ActionPause] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_MIDDLE_RIGHT}
+
+	menu := make(GamepadBindings)
+
+	menu[ActionMoveUp] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_LEFT_FACE_UP}
+	menu[ActionMoveDown] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_LEFT_FACE_DOWN}
+	menu[ActionMoveLeft] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_LEFT_FACE_LEFT}
+	menu[ActionMoveRight] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_LEFT_FACE_RIGHT}
+	menu[ActionValidate] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_RIGHT_FACE_DOWN}
+	menu[ActionBack] = []rl.GamepadButton{rl.GAMEPAD_BUTTON_RIGHT_FACE_RIGHT, rl.GAMEPAD_BUTTON_MIDDLE_RIGHT}
+
+	return map[InputContext]GamepadBindings{
+		GameplayContext: b,
+		MenuContext:     menu,
+	}
+}
+
+// Gamepad follows the first connected gamepad, picking up gamepads plugged
+// or unplugged while the game runs.
+type Gamepad struct {
+	id       int32
+	bindings map[InputContext]GamepadBindings
+}
+
+func NewGamepad() *Gamepad {
+	return &Gamepad{id: -1, bindings: DefaultGamepadBindings()}
+}
+
+// Update checks for plugged and unplugged gamepads, it is called once per step.
+func (g *Gamepad) Update() {
+	if g.Connected() && rl.IsGamepadAvailable(g.id) {
+		return
+	}
+
+	if g.Connected() {
+		fmt.Println("Gamepad disconnected")
+		g.id = -1
+	}
+
+	for id := int32(0); id < maxGamepads; id++ {
+		if rl.IsGamepadAvailable(id) {
+			g.id = id
+			fmt.Println("Gamepad connected:", rl.GetGamepadName(id))
+			return
+		}
+	}
+}
+
+func (g Gamepad) Connected() bool {
+	return g.id >= 0
+}
+
+// Down reports if any button bound to the action in the context is held.
+func (g Gamepad) Down(context InputContext, action Action) bool {
+	if !g.Connected() {
+		return false
+	}
+
+	for _, button := range g.bindings[context][action] {
+		if rl.IsGamepadButtonDown(g.id, int32(button)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Axis gives the position of an axis, 0 inside the deadzone.
+func (g Gamepad) Axis(axis rl.GamepadAxis) float32 {
+	if !g.Connected() {
+		return 0
+	}
+
+	value := rl.GetGamepadAxisMovement(g.id, int32(axis))
+	if value > -GamepadDeadzone && value < GamepadDeadzone {
+		return 0
+	}
+
+	return value
+}
+
+// LeftStick gives the movement direction of the left stick.
+func (g Gamepad) LeftStick() sim.Vector2 {
+	return sim.Vector2{X: g.Axis(rl.GAMEPAD_AXIS_LEFT_X), Y: g.Axis(rl.GAMEPAD_AXIS_LEFT_Y)}
+}
+
+// StickStrength gives how far an axis is pushed past the deadzone, from 0 to 1.
+func StickStrength(value float32) float32 {
+	if value < 0 {
+		value = -value
+	}
+
+	strength := (value - GamepadDeadzone) / (1 - GamepadDeadzone)
+	if strength < 0 {
+		return 0
+	} else if strength > 1 {
+		return 1
+	}
+
+	return strength
+}
+
+// RightStick gives the normalized direction of the right stick, the boolean
+// is false when the stick is at rest.
+func (g Gamepad) RightStick() (sim.Vector2, bool) {
+	x := g.Axis(rl.GAMEPAD_AXIS_RIGHT_X)
+	y := g.Axis(rl.GAMEPAD_AXIS_RIGHT_Y)
+
+	length := float32(math.Sqrt(float64(x*x + y*y)))
+	if length < GamepadDeadzone {
+		return sim.Vector2{}, false
+	}
+
+	return sim.Vector2{X: x / length, Y: y / length}, true
+}
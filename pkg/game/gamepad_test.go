@@ -0,0 +1,29 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestStickStrength(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float32
+		want  float32
+	}{
+		{name: "at rest", value: 0, want: 0},
+		{name: "inside the deadzone", value: 0.2, want: 0},
+		{name: "edge of the deadzone", value: GamepadDeadzone, want: 0},
+		{name: "half way", value: 0.625, want: 0.5},
+		{name: "half way to the left", value: -0.625, want: 0.5},
+		{name: "pushed all the way", value: 1, want: 1},
+		{name: "past the edge", value: -1.2, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StickStrength(tt.value); got != tt.want {
+				t.Errorf("StickStrength(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
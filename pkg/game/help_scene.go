@@ -0,0 +1,108 @@
+package game
+
+import (
+	"fmt"
+
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// helpText explains the game, one line under the other, with the keys bound in
+// the settings.
+func helpText(bindings KeyBindings) []string {
+	return []string{
+		"In random game mode you have 30 seconds to catch all the stars",
+		"Your score depends on how much remaining time you still get.",
+		"To achieve your mission you have access to multiple fast travel skills",
+		"",
+		fmt.Sprintf("Teeworlds fan ? You can use a grappling hook using %v !", BindingsText(bindings[ActionHook])),
+		fmt.Sprintf("Already played portal ? You can fire your portal gun using %v !", BindingsText(bindings[ActionPortal])),
+		fmt.Sprintf("And finally, %v dashes toward your mouse, in one of eight directions.", BindingsText(bindings[ActionDash])),
+	}
+}
+
+// HelpScene is pushed over a game scene, which stays frozen until help is closed.
+type HelpScene struct {
+	inputManager *InputManager
+	sceneManager *SceneManager
+}
+
+func NewHelpScene(sm *SceneManager) *HelpScene {
+	hs := &HelpScene{}
+
+	im := NewInputManager(GameplayContext, sm.Settings(), sm.Gamepad())
+	hs.inputManager = &im
+	hs.sceneManager = sm
+
+	return hs
+}
+
+func (hs *HelpScene) Init() {
+
+}
+
+func (hs *HelpScene) UpdateInputs() {
+	hs.inputManager.Update()
+}
+
+func (hs *HelpScene) ClearInputs() {
+	hs.inputManager.Clear()
+}
+
+func (hs *HelpScene) ResetInputs() {
+	hs.inputManager.Reset()
+}
+
+func (hs *HelpScene) End() {
+
+}
+
+func (hs *HelpScene) HandleEvents() {
+	hs.sceneManager.Dispatch(hs.inputManager.events, ActionHandlers{
+		ActionHelp: hs.sceneManager.PopScene,
+		ActionQuit: func() {
+			hs.sceneManager.SwapSceneWith("main_menu", NewFade())
+		},
+		// The game beneath is frozen while help is open
+		ActionMoveLeft:  nil,
+		ActionMoveRight: nil,
+		ActionJump:      nil,
+		ActionStopJump:  nil,
+		ActionHook:      nil,
+		ActionStopHook:  nil,
+		ActionReelIn:    nil,
+		ActionReelOut:   nil,
+		ActionDash:      nil,
+		ActionPortal:    nil,
+		ActionValidate:  nil,
+		ActionPause:     nil,
+	})
+}
+
+func (hs *HelpScene) Update(deltaTime float32) {
+
+}
+
+func (hs HelpScene) ShouldExit() bool {
+	return false
+}
+
+func (hs HelpScene) UpdateBelow() bool {
+	return false
+}
+
+func (hs HelpScene) DrawBelow() bool {
+	return false
+}
+
+func (hs HelpScene) Draw(factor float64) {
+	rl.ClearBackground(rl.RayWhite)
+
+	bindings := hs.sceneManager.Settings().KeyBindings
+
+	rl.DrawText(fmt.Sprintf("Help menu press %v again to close.", BindingsText(bindings[ActionHelp])), 250, 50, 50, rl.Black)
+	rl.DrawText(fmt.Sprintf("Press %v to leave", BindingsText(bindings[ActionQuit])), 300, 110, 50, rl.Black)
+
+	for i, line := range helpText(bindings) {
+		rl.DrawText(line, 20, int32(200+i*30), 30, rl.Black)
+	}
+}
@@ -0,0 +1,27 @@
+package game
+
+import (
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// drawHud shows the state of the player abilities at the bottom left of the screen.
+func drawHud(player *sim.Player) {
+	y := rl.GetScreenHeight() - 40
+
+	drawCharges("Air jumps", player.AirJumpsLeft(), player.AirJumps(), y)
+	drawCharges("Air dashes", player.AirDashesLeft(), player.AirDashes(), y-25)
+}
+
+// drawCharges draws one dot per charge of an ability, the used ones grayed out.
+func drawCharges(name string, left, total int, y int32) {
+	rl.DrawText(name, 10, y, 20, rl.Black)
+	for i := 0; i < total; i++ {
+		color := rl.LightGray
+		if i < left {
+			color = rl.SkyBlue
+		}
+
+		rl.DrawCircle(int32(135+i*25), y+10, 8, color)
+	}
+}
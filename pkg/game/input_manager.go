@@ -1,98 +1,266 @@
 package game
 
 import (
-	"time"
-
+	"example.com/rplat/pkg/sim"
 	rl "github.com/chunqian/go-raylib/raylib"
 )
 
-var TimeBetweenPauses = 100 * time.Millisecond
-var lastPauseTime = time.Now()
+// InputContext selects the action map an input manager reads. Every context
+// shares the keyboard, mouse and gamepad, each one has its own bindings.
+type InputContext int
+
+const (
+	GameplayContext InputContext = iota
+	MenuContext
+)
+
+// actionMap lists the actions read in a context. Held actions fire on every
+// step they are held, the others fire once when pressed. Repeated actions fire
+// again while held, after a delay. Some actions fire a second action when
+// released.
+type actionMap struct {
+	actions  []Action
+	held     []Action
+	repeated []Action
+	released map[Action]Action
+}
+
+var actionMaps = map[InputContext]actionMap{
+	GameplayContext: {
+		actions:  []Action{ActionPause, ActionHelp, ActionQuit, ActionMoveLeft, ActionMoveRight, ActionJump, ActionHook, ActionReelIn, ActionReelOut, ActionDash, ActionPortal, ActionValidate},
+		held:     []Action{ActionMoveLeft, ActionMoveRight, ActionReelIn, ActionReelOut},
+		released: map[Action]Action{ActionJump: ActionStopJump, ActionHook: ActionStopHook},
+	},
+	MenuContext: {
+		actions:  []Action{ActionMoveUp, ActionMoveDown, ActionMoveLeft, ActionMoveRight, ActionValidate, ActionBack, ActionClick},
+		repeated: []Action{ActionMoveUp, ActionMoveDown, ActionMoveLeft, ActionMoveRight},
+	},
+}
+
+// Held directions repeat after a delay, to scroll lists and change values
+// without pressing again, both in steps
+const menuRepeatDelay = 40
+const menuRepeatInterval = 8
+
+// Sticks pushed further than this navigate menus
+const menuStickThreshold = 0.5
 
 type InputManager struct {
-	inputMap map[string]int32
-	events   []string
+	context  InputContext
+	settings *Settings
+	gamepad  *Gamepad
+	actions  ActionStates
+	// Tells if the player hook is out, see FollowHook
+	hookLaunched func() bool
+	// The player aims with the device used last, the mouse or the right stick
+	aimWithStick bool
+	aimDirection sim.Vector2
+	mouse        sim.Vector2
+	mouseMoved   bool
+	// How fast the player moves, from 0 to 1
+	moveStrength float32
+	events       []Action
 }
 
-func NewInputManager() InputManager {
+// NewInputManager reads the bindings of the context from the settings, so
+// rebinding a key takes effect right away, and the gamepad in use.
+func NewInputManager(context InputContext, settings *Settings, gamepad *Gamepad) InputManager {
 	im := InputManager{}
-	m := make(map[string]int32)
-
-	m["jump"] = int32(rl.KEY_SPACE)
-	m["move_left"] = int32(rl.KEY_A)
-	m["move_right"] = int32(rl.KEY_D)
-	m["hook"] = int32(rl.KEY_ENTER)
-	m["mouse_hook"] = int32(rl.MOUSE_RIGHT_BUTTON)
-	m["dash"] = int32(rl.KEY_LEFT_SHIFT)
-	m["portal"] = int32(rl.MOUSE_LEFT_BUTTON)
-	m["validate"] = int32(rl.KEY_ENTER)
-	m["help"] = int32(rl.KEY_H)
-	m["quit"] = int32(rl.KEY_BACKSPACE)
-
-	im.inputMap = m
+
+	im.context = context
+	im.settings = settings
+	im.gamepad = gamepad
+	im.actions = make(ActionStates)
+	im.aimDirection = sim.Vector2{X: 1, Y: 0}
 	return im
 }
 
+// Update reads the actions once per simulation step.
 func (im *InputManager) Update() {
-	kbHook := false
+	im.readActions()
+	im.updateMouse()
 
-	if Debug && time.Since(lastPauseTime) > TimeBetweenPauses && rl.IsKeyDown(int32(rl.KEY_P)) {
-		lastPauseTime = time.Now()
-		im.events = append(im.events, "pause")
-	}
+	for _, action := range actionMaps[im.context].actions {
+		state := im.actions[action]
 
-	if !Pause {
-		if rl.IsKeyPressed(im.inputMap["help"]) {
-			im.events = append(im.events, "help")
+		if im.context == GameplayContext && action == ActionHook {
+			im.updateHook(state)
+		} else if im.isHeld(action) && state.Down {
+			im.events = append(im.events, action)
+		} else if !im.isHeld(action) && state.Pressed {
+			im.events = append(im.events, action)
+		} else if im.isRepeated(action) && repeats(state) {
+			im.events = append(im.events, action)
 		}
 
-		if rl.IsKeyPressed(im.inputMap["quit"]) {
-			im.events = append(im.events, "quit")
+		if released, ok := im.releasedAction(action); ok && state.Released {
+			im.events = append(im.events, released)
 		}
+	}
+}
 
-		if rl.IsKeyDown(im.inputMap["move_left"]) {
-			im.events = append(im.events, "move_left")
-		}
+// updateHook launches the hook on a press. When the hook is toggled, a press
+// releases the hook instead if it is out.
+func (im *InputManager) updateHook(hook ActionState) {
+	if !hook.Pressed {
+		return
+	}
 
-		if rl.IsKeyDown(im.inputMap["move_right"]) {
-			im.events = append(im.events, "move_right")
-		}
+	if im.settings.ToggleHook && im.hookLaunched != nil && im.hookLaunched() {
+		im.events = append(im.events, ActionStopHook)
+	} else {
+		im.events = append(im.events, ActionHook)
+	}
+}
 
-		if rl.IsKeyDown(im.inputMap["jump"]) {
-			im.events = append(im.events, "jump")
-		}
+// releasedAction gives the action fired when the action is released, if any.
+// A toggled hook is only released by pressing it again.
+func (im InputManager) releasedAction(action Action) (Action, bool) {
+	if action == ActionHook && im.settings.ToggleHook {
+		return ActionNone, false
+	}
 
-		if rl.IsKeyDown(im.inputMap["hook"]) {
-			im.events = append(im.events, "hook")
-			kbHook = true
-		}
+	released, ok := actionMaps[im.context].released[action]
+	return released, ok
+}
 
-		if rl.IsKeyUp(im.inputMap["hook"]) && kbHook {
-			im.events = append(im.events, "stop_hook")
-		}
+// FollowHook gives the actual state of the hook, a toggled hook press
+// releases a hook that is out, even one that came back or was dropped on its
+// own, and launches one otherwise.
+func (im *InputManager) FollowHook(launched func() bool) {
+	im.hookLaunched = launched
+}
 
-		if rl.IsMouseButtonDown(im.inputMap["mouse_hook"]) {
-			im.events = append(im.events, "hook")
-		}
+func (im InputManager) isHeld(action Action) bool {
+	return hasAction(actionMaps[im.context].held, action)
+}
 
-		if rl.IsMouseButtonUp(im.inputMap["mouse_hook"]) && !kbHook {
-			im.events = append(im.events, "stop_hook")
-		}
+func (im InputManager) isRepeated(action Action) bool {
+	return hasAction(actionMaps[im.context].repeated, action)
+}
 
-		if rl.IsKeyDown(im.inputMap["dash"]) {
-			im.events = append(im.events, "dash")
+func hasAction(actions []Action, action Action) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
 		}
+	}
+
+	return false
+}
+
+// repeats reports if a held action fires again this step.
+func repeats(state ActionState) bool {
+	held := state.HeldSteps - menuRepeatDelay
+	return state.Down && held >= 0 && held%menuRepeatInterval == 0
+}
+
+func (im *InputManager) readActions() {
+	stick := im.gamepad.LeftStick()
+	keyMove := false
 
-		if rl.IsMouseButtonDown(im.inputMap["portal"]) {
-			im.events = append(im.events, "portal")
+	for _, action := range actionMaps[im.context].actions {
+		down := im.settings.Bindings(im.context).Down(action) || im.gamepad.Down(im.context, action)
+
+		if im.context == GameplayContext {
+			switch action {
+			case ActionMoveLeft:
+				keyMove = keyMove || down
+				down = down || stick.X < 0
+			case ActionMoveRight:
+				keyMove = keyMove || down
+				down = down || stick.X > 0
+			}
+		} else {
+			switch action {
+			case ActionMoveLeft:
+				down = down || stick.X < -menuStickThreshold
+			case ActionMoveRight:
+				down = down || stick.X > menuStickThreshold
+			case ActionMoveUp:
+				down = down || stick.Y < -menuStickThreshold
+			case ActionMoveDown:
+				down = down || stick.Y > menuStickThreshold
+			}
 		}
 
-		if rl.IsKeyDown(im.inputMap["validate"]) {
-			im.events = append(im.events, "validate")
+		im.actions.Update(action, down)
+	}
+
+	// Keys move at full speed, the stick as fast as it is pushed
+	im.moveStrength = 1
+	if !keyMove && stick.X != 0 {
+		im.moveStrength = StickStrength(stick.X)
+	}
+}
+
+func (im *InputManager) updateMouse() {
+	mouse := fromRlVector2(rl.GetMousePosition())
+	im.mouseMoved = mouse != im.mouse
+	if im.mouseMoved {
+		im.aimWithStick = false
+		im.mouse = mouse
+	}
+
+	if direction, ok := im.gamepad.RightStick(); ok {
+		im.aimWithStick = true
+		im.aimDirection = direction
+	}
+}
+
+// Reset takes the actions held right now as already held, so they are not
+// seen as new presses. It is called when the scene gets the inputs back.
+// Actions released while another scene had the inputs fire their release,
+// like the hook let go during the pause.
+func (im *InputManager) Reset() {
+	previous := im.actions
+	im.actions = make(ActionStates)
+	im.readActions()
+	im.mouse = fromRlVector2(rl.GetMousePosition())
+
+	for _, action := range actionMaps[im.context].actions {
+		state := im.actions[action]
+		state.Pressed = false
+		im.actions[action] = state
+
+		if released, ok := im.releasedAction(action); ok && previous[action].Down && !state.Down {
+			im.events = append(im.events, released)
 		}
 	}
 }
 
+// MoveStrength gives how fast the player asks to move during the step, from 0 to 1.
+func (im InputManager) MoveStrength() float32 {
+	return im.moveStrength
+}
+
+// Action gives the state of an action during the current step.
+func (im InputManager) Action(action Action) ActionState {
+	return im.actions[action]
+}
+
+func (im InputManager) MousePosition() sim.Vector2 {
+	return im.mouse
+}
+
+// MouseMoved reports if the mouse moved during the current step.
+func (im InputManager) MouseMoved() bool {
+	return im.mouseMoved
+}
+
+// AimPosition gives the position the player aims at with the mouse, or in the
+// direction of the right stick from origin when the stick was used last.
+func (im InputManager) AimPosition(origin sim.Vector2) sim.Vector2 {
+	if !im.aimWithStick {
+		return im.mouse
+	}
+
+	return sim.Vector2{
+		X: origin.X + im.aimDirection.X*GamepadAimDistance,
+		Y: origin.Y + im.aimDirection.Y*GamepadAimDistance,
+	}
+}
+
 func (im *InputManager) Clear() {
 	im.events = nil
 }
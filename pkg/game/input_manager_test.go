@@ -0,0 +1,30 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestMenuRepeats(t *testing.T) {
+	states := make(ActionStates)
+
+	var fired []int
+	for step := 1; step <= menuRepeatDelay+2*menuRepeatInterval; step++ {
+		if repeats(states.Update(ActionMoveDown, true)) {
+			fired = append(fired, step)
+		}
+	}
+
+	want := []int{menuRepeatDelay, menuRepeatDelay + menuRepeatInterval, menuRepeatDelay + 2*menuRepeatInterval}
+	if len(fired) != len(want) {
+		t.Fatalf("repeated on steps %v, want %v", fired, want)
+	}
+	for i := range want {
+		if fired[i] != want[i] {
+			t.Errorf("repeated on steps %v, want %v", fired, want)
+		}
+	}
+
+	if repeats(states.Update(ActionMoveDown, false)) {
+		t.Error("released action repeats")
+	}
+}
@@ -0,0 +1,293 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// KeyBinding is a key or a mouse button, optionally held with modifier keys.
+// It is written as text in the settings file, like "SPACE", "CTRL+D" or
+// "MOUSE_LEFT".
+type KeyBinding struct {
+	Key     int32
+	Mouse   bool
+	Shift   bool
+	Control bool
+	Alt     bool
+}
+
+// KeyBindings gives the bindings of each action, any of them triggers it.
+type KeyBindings map[Action][]KeyBinding
+
+func DefaultKeyBindings() KeyBindings {
+	b := make(KeyBindings)
+
+	b[ActionJump] = []KeyBinding{Key(rl.KEY_SPACE)}
+	b[ActionMoveLeft] = []KeyBinding{Key(rl.KEY_A)}
+	b[ActionMoveRight] = []KeyBinding{Key(rl.KEY_D)}
+	b[ActionHook] = []KeyBinding{Key(rl.KEY_ENTER), MouseButton(rl.MOUSE_RIGHT_BUTTON)}
+	b[ActionReelIn] = []KeyBinding{Key(rl.KEY_W)}
+	b[ActionReelOut] = []KeyBinding{Key(rl.KEY_S)}
+	b[ActionDash] = []KeyBinding{Key(rl.KEY_LEFT_SHIFT)}
+	b[ActionPortal] = []KeyBinding{MouseButton(rl.MOUSE_LEFT_BUTTON)}
+	b[ActionValidate] = []KeyBinding{Key(rl.KEY_ENTER)}
+	b[ActionHelp] = []KeyBinding{Key(rl.KEY_H)}
+	b[ActionQuit] = []KeyBinding{Key(rl.KEY_BACKSPACE)}
+	b[ActionPause] = []KeyBinding{Key(rl.KEY_ESCAPE)}
+
+	return b
+}
+
+func DefaultMenuKeyBindings() KeyBindings {
+	b := make(KeyBindings)
+
+	b[ActionMoveUp] = []KeyBinding{Key(rl.KEY_UP)}
+	b[ActionMoveDown] = []KeyBinding{Key(rl.KEY_DOWN)}
+	b[ActionMoveLeft] = []KeyBinding{Key(rl.KEY_LEFT)}
+	b[ActionMoveRight] = []KeyBinding{Key(rl.KEY_RIGHT)}
+	b[ActionValidate] = []KeyBinding{Key(rl.KEY_ENTER)}
+	b[ActionBack] = []KeyBinding{Key(rl.KEY_ESCAPE)}
+	b[ActionClick] = []KeyBinding{MouseButton(rl.MOUSE_LEFT_BUTTON)}
+
+	return b
+}
+
+func Key(key rl.KeyboardKey) KeyBinding {
+	return KeyBinding{Key: int32(key)}
+}
+
+func MouseButton(button rl.MouseButton) KeyBinding {
+	return KeyBinding{Key: int32(button), Mouse: true}
+}
+
+// active reports if the binding can trigger its action with the held modifiers.
+// A binding gives way to a held binding of the same key with more modifiers, so
+// CTRL+D bound to one action does not also trigger the action bound to D.
+func (kb KeyBindings) active(b KeyBinding, held modifiers) bool {
+	return b.matches(held) && !kb.overridden(b, held)
+}
+
+// overridden reports if a binding of the same key with more modifiers is held.
+func (kb KeyBindings) overridden(b KeyBinding, held modifiers) bool {
+	for _, bindings := range kb {
+		for _, other := range bindings {
+			if other.Key == b.Key && other.Mouse == b.Mouse && other.extends(b) && other.matches(held) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// extends reports if the binding needs every modifier of the other one and more.
+func (b KeyBinding) extends(other KeyBinding) bool {
+	if (other.Shift && !b.Shift) || (other.Control && !b.Control) || (other.Alt && !b.Alt) {
+		return false
+	}
+
+	return b.modifierCount() > other.modifierCount()
+}
+
+func (b KeyBinding) modifierCount() int {
+	count := 0
+	for _, held := range []bool{b.Shift, b.Control, b.Alt} {
+		if held {
+			count++
+		}
+	}
+
+	return count
+}
+
+// modifiers are the modifier keys held down.
+type modifiers struct {
+	shift   bool
+	control bool
+	alt     bool
+}
+
+// matches reports if the modifiers of the binding are held. Bindings without
+// modifiers ignore the modifier keys, so moving still works while holding shift
+// to dash, unless a binding with the modifiers exists.
+func (b KeyBinding) matches(held modifiers) bool {
+	return (!b.Shift || held.shift) && (!b.Control || held.control) && (!b.Alt || held.alt)
+}
+
+// withModifiers gives the binding of a key held with the modifiers.
+func withModifiers(key int32, mouse bool, held modifiers) KeyBinding {
+	return KeyBinding{Key: key, Mouse: mouse, Shift: held.shift, Control: held.control, Alt: held.alt}
+}
+
+func (b KeyBinding) String() string {
+	var parts []string
+
+	if b.Control {
+		parts = append(parts, "CTRL")
+	}
+	if b.Shift {
+		parts = append(parts, "SHIFT")
+	}
+	if b.Alt {
+		parts = append(parts, "ALT")
+	}
+
+	if b.Mouse {
+		parts = append(parts, mouseNames[b.Key])
+	} else {
+		parts = append(parts, KeyName(b.Key))
+	}
+
+	return strings.Join(parts, "+")
+}
+
+func ParseKeyBinding(text string) (KeyBinding, error) {
+	var b KeyBinding
+
+	parts := strings.Split(strings.ToUpper(text), "+")
+	for _, part := range parts[:len(parts)-1] {
+		switch part {
+		case "CTRL":
+			b.Control = true
+		case "SHIFT":
+			b.Shift = true
+		case "ALT":
+			b.Alt = true
+		default:
+			return b, fmt.Errorf("unknown modifier %s in key binding %s", part, text)
+		}
+	}
+
+	name := parts[len(parts)-1]
+	for key, keyName := range keyNames {
+		if keyName == name {
+			b.Key = key
+			return b, nil
+		}
+	}
+
+	for button, buttonName := range mouseNames {
+		if buttonName == name {
+			b.Key = button
+			b.Mouse = true
+			return b, nil
+		}
+	}
+
+	return b, fmt.Errorf("unknown key %s in key binding %s", name, text)
+}
+
+func (b KeyBinding) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.String())
+}
+
+func (b *KeyBinding) UnmarshalJSON(data []byte) error {
+	var text string
+
+	err := json.Unmarshal(data, &text)
+	if err != nil {
+		return err
+	}
+
+	*b, err = ParseKeyBinding(text)
+	return err
+}
+
+// UnmarshalJSON skips unknown actions and keys one at a time with a warning,
+// so a typo in a hand edited settings file keeps the other bindings. Actions
+// left without any valid key get their default keys back.
+func (kb *KeyBindings) UnmarshalJSON(data []byte) error {
+	var raw map[string][]json.RawMessage
+
+	err := json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	bindings := make(KeyBindings)
+	for name, texts := range raw {
+		action, err := ParseAction(name)
+		if err != nil {
+			fmt.Println("warning: ignoring key bindings:", err)
+			continue
+		}
+
+		var keys []KeyBinding
+		for _, text := range texts {
+			var b KeyBinding
+			if err := json.Unmarshal(text, &b); err != nil {
+				fmt.Printf("warning: ignoring key binding of %v: %v\n", action, err)
+				continue
+			}
+			keys = append(keys, b)
+		}
+
+		if len(keys) > 0 || len(texts) == 0 {
+			bindings[action] = keys
+		}
+	}
+
+	*kb = bindings
+	return nil
+}
+
+var keyNames = map[int32]string{
+	int32(rl.KEY_SPACE):         "SPACE",
+	int32(rl.KEY_ENTER):         "ENTER",
+	int32(rl.KEY_ESCAPE):        "ESCAPE",
+	int32(rl.KEY_BACKSPACE):     "BACKSPACE",
+	int32(rl.KEY_TAB):           "TAB",
+	int32(rl.KEY_RIGHT):         "RIGHT",
+	int32(rl.KEY_LEFT):          "LEFT",
+	int32(rl.KEY_DOWN):          "DOWN",
+	int32(rl.KEY_UP):            "UP",
+	int32(rl.KEY_LEFT_SHIFT):    "LEFT_SHIFT",
+	int32(rl.KEY_LEFT_CONTROL):  "LEFT_CTRL",
+	int32(rl.KEY_LEFT_ALT):      "LEFT_ALT",
+	int32(rl.KEY_RIGHT_SHIFT):   "RIGHT_SHIFT",
+	int32(rl.KEY_RIGHT_CONTROL): "RIGHT_CTRL",
+	int32(rl.KEY_RIGHT_ALT):     "RIGHT_ALT",
+}
+
+var mouseNames = map[int32]string{
+	int32(rl.MOUSE_LEFT_BUTTON):   "MOUSE_LEFT",
+	int32(rl.MOUSE_RIGHT_BUTTON):  "MOUSE_RIGHT",
+	int32(rl.MOUSE_MIDDLE_BUTTON): "MOUSE_MIDDLE",
+}
+
+func init() {
+	// Letters and digits use their ASCII code as key code
+	for c := 'A'; c <= 'Z'; c++ {
+		keyNames[int32(c)] = string(c)
+	}
+
+	for c := '0'; c <= '9'; c++ {
+		keyNames[int32(c)] = string(c)
+	}
+}
+
+// KeyName gives a readable name for a key code.
+func KeyName(key int32) string {
+	if name, ok := keyNames[key]; ok {
+		return name
+	}
+
+	return "?"
+}
+
+// BindingsText lists the bindings of an action, for menus and help screens.
+func BindingsText(bindings []KeyBinding) string {
+	var names []string
+	for _, b := range bindings {
+		names = append(names, b.String())
+	}
+
+	if len(names) == 0 {
+		return "-"
+	}
+
+	return strings.Join(names, " / ")
+}
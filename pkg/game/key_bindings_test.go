@@ -0,0 +1,137 @@
+package game
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+func TestParseKeyBinding(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    KeyBinding
+		wantErr bool
+	}{
+		{text: "SPACE", want: Key(rl.KEY_SPACE)},
+		{text: "d", want: Key(rl.KEY_D)},
+		{text: "CTRL+D", want: KeyBinding{Key: int32(rl.KEY_D), Control: true}},
+		{text: "ctrl+shift+alt+7", want: KeyBinding{Key: '7', Shift: true, Control: true, Alt: true}},
+		{text: "MOUSE_RIGHT", want: MouseButton(rl.MOUSE_RIGHT_BUTTON)},
+		{text: "SHIFT+MOUSE_LEFT", want: KeyBinding{Key: int32(rl.MOUSE_LEFT_BUTTON), Mouse: true, Shift: true}},
+		{text: "LEFT_SHIFT", want: Key(rl.KEY_LEFT_SHIFT)},
+		{text: "SUPER+D", wantErr: true},
+		{text: "F13", wantErr: true},
+		{text: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseKeyBinding(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeyBinding(%q) error = %v, want error %v", tt.text, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("ParseKeyBinding(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+
+			// Written back the way the settings file does, the binding reads the same
+			again, err := ParseKeyBinding(got.String())
+			if err != nil || again != got {
+				t.Errorf("ParseKeyBinding(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestKeyBindingsUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want KeyBindings
+	}{
+		{
+			name: "valid bindings",
+			data: `{"jump": ["SPACE", "W"], "dash": ["CTRL+D"]}`,
+			want: KeyBindings{
+				ActionJump: {Key(rl.KEY_SPACE), Key(rl.KEY_W)},
+				ActionDash: {{Key: int32(rl.KEY_D), Control: true}},
+			},
+		},
+		{
+			name: "unknown action",
+			data: `{"fly": ["F"], "jump": ["SPACE"]}`,
+			want: KeyBindings{ActionJump: {Key(rl.KEY_SPACE)}},
+		},
+		{
+			name: "unknown key",
+			data: `{"jump": ["SPACEBAR", "W"]}`,
+			want: KeyBindings{ActionJump: {Key(rl.KEY_W)}},
+		},
+		{
+			// Left out so the settings give the default keys back
+			name: "no valid key",
+			data: `{"jump": ["SPACEBAR"]}`,
+			want: KeyBindings{},
+		},
+		{
+			name: "unbound action",
+			data: `{"help": []}`,
+			want: KeyBindings{ActionHelp: nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got KeyBindings
+			if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bindings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeyBindingsActive(t *testing.T) {
+	d := Key(rl.KEY_D)
+	ctrlD := KeyBinding{Key: int32(rl.KEY_D), Control: true}
+	ctrlShiftD := KeyBinding{Key: int32(rl.KEY_D), Control: true, Shift: true}
+	bindings := KeyBindings{
+		ActionMoveRight: {d},
+		ActionDash:      {ctrlD},
+		ActionPortal:    {ctrlShiftD},
+		ActionJump:      {Key(rl.KEY_SPACE)},
+	}
+
+	tests := []struct {
+		name    string
+		binding KeyBinding
+		held    modifiers
+		want    bool
+	}{
+		{name: "no modifier", binding: d, held: modifiers{}, want: true},
+		{name: "unbound modifier held", binding: d, held: modifiers{alt: true}, want: true},
+		{name: "overridden by a binding with the modifier", binding: d, held: modifiers{control: true}, want: false},
+		{name: "binding with the modifier", binding: ctrlD, held: modifiers{control: true}, want: true},
+		{name: "modifier not held", binding: ctrlD, held: modifiers{}, want: false},
+		{name: "overridden by a binding with more modifiers", binding: ctrlD, held: modifiers{control: true, shift: true}, want: false},
+		{name: "binding with every modifier", binding: ctrlShiftD, held: modifiers{control: true, shift: true}, want: true},
+		{name: "other key", binding: Key(rl.KEY_SPACE), held: modifiers{control: true, shift: true}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bindings.active(tt.binding, tt.held); got != tt.want {
+				t.Errorf("active(%v) with %+v held = %v, want %v", tt.binding, tt.held, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package game
+
+import (
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// raylib side of the key bindings, reading the keyboard and the mouse.
+
+// Down reports if any binding of the action is held.
+func (kb KeyBindings) Down(action Action) bool {
+	held := heldModifiers()
+
+	for _, b := range kb[action] {
+		if kb.active(b, held) && b.down() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (b KeyBinding) down() bool {
+	if b.Mouse {
+		return rl.IsMouseButtonDown(b.Key)
+	}
+
+	return rl.IsKeyDown(b.Key)
+}
+
+func heldModifiers() modifiers {
+	return modifiers{
+		shift:   rl.IsKeyDown(int32(rl.KEY_LEFT_SHIFT)) || rl.IsKeyDown(int32(rl.KEY_RIGHT_SHIFT)),
+		control: rl.IsKeyDown(int32(rl.KEY_LEFT_CONTROL)) || rl.IsKeyDown(int32(rl.KEY_RIGHT_CONTROL)),
+		alt:     rl.IsKeyDown(int32(rl.KEY_LEFT_ALT)) || rl.IsKeyDown(int32(rl.KEY_RIGHT_ALT)),
+	}
+}
+
+func isModifierKey(key int32) bool {
+	switch rl.KeyboardKey(key) {
+	case rl.KEY_LEFT_SHIFT, rl.KEY_RIGHT_SHIFT, rl.KEY_LEFT_CONTROL, rl.KEY_RIGHT_CONTROL, rl.KEY_LEFT_ALT, rl.KEY_RIGHT_ALT:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+package game
+
+import (
+	"fmt"
+
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// MapScene is implemented by game scenes that can be played on any map.
+type MapScene interface {
+	SetMapPath(path string)
+	// ResetMapPath goes back to the map given on the command line
+	ResetMapPath()
+}
+
+// Game modes offered by the level select, by scene name
+var levelSelectModes = []string{"random_game", "tutorial_game"}
+var levelSelectModeNames = map[string]string{
+	"random_game":   "Random game",
+	"tutorial_game": "Tutorial",
+}
+
+const levelSelectVisibleMaps = 12
+
+// LevelSelectScene lists the maps of the maps directory and starts the chosen
+// game mode on the chosen map.
+type LevelSelectScene struct {
+	inputManager *InputManager
+	selectedItem int
+	selectedMode int
+	sceneManager *SceneManager
+	mapsDir      string
+	maps         []MapInfo
+	scores       Scores
+}
+
+func NewLevelSelectScene(sm *SceneManager, options Options) *LevelSelectScene {
+	lss := &LevelSelectScene{}
+
+	im := NewInputManager(MenuContext, sm.Settings(), sm.Gamepad())
+	lss.inputManager = &im
+	lss.sceneManager = sm
+	lss.mapsDir = options.MapsDir
+
+	return lss
+}
+
+func (lss *LevelSelectScene) Init() {
+	var err error
+
+	lss.selectedItem = 0
+	lss.maps, err = ScanMaps(lss.mapsDir)
+	if err != nil {
+		fmt.Println("error: could not list maps:", err)
+	}
+
+	lss.scores, err = LoadScores()
+	if err != nil {
+		fmt.Println("error: could not load scores:", err)
+	}
+}
+
+func (lss *LevelSelectScene) UpdateInputs() {
+	lss.inputManager.Update()
+}
+
+func (lss *LevelSelectScene) ClearInputs() {
+	lss.inputManager.Clear()
+}
+
+func (lss *LevelSelectScene) ResetInputs() {
+	lss.inputManager.Reset()
+}
+
+func (lss *LevelSelectScene) End() {
+
+}
+
+func (lss *LevelSelectScene) HandleEvents() {
+	lss.sceneManager.Dispatch(lss.inputManager.events, ActionHandlers{
+		ActionMoveUp: func() {
+			lss.selectedItem = cycle(lss.selectedItem, -1, len(lss.maps))
+		},
+		ActionMoveDown: func() {
+			lss.selectedItem = cycle(lss.selectedItem, 1, len(lss.maps))
+		},
+		ActionMoveLeft: func() {
+			lss.selectedMode = cycle(lss.selectedMode, -1, len(levelSelectModes))
+		},
+		ActionMoveRight: func() {
+			lss.selectedMode = cycle(lss.selectedMode, 1, len(levelSelectModes))
+		},
+		ActionBack:  lss.sceneManager.PopScene,
+		ActionClick: nil,
+		ActionValidate: func() {
+			if len(lss.maps) == 0 {
+				return
+			}
+
+			mode := levelSelectModes[lss.selectedMode]
+			if ms, ok := lss.sceneManager.Prepare(mode).(MapScene); ok {
+				ms.SetMapPath(lss.maps[lss.selectedItem].Path)
+			}
+			lss.sceneManager.SwapSceneWith(mode, NewFade())
+		},
+	})
+}
+
+func (lss *LevelSelectScene) Update(deltaTime float32) {
+
+}
+
+func (lss LevelSelectScene) ShouldExit() bool {
+	return false
+}
+
+func (lss LevelSelectScene) UpdateBelow() bool {
+	return false
+}
+
+func (lss LevelSelectScene) DrawBelow() bool {
+	return false
+}
+
+func (lss LevelSelectScene) Draw(factor float64) {
+	rl.ClearBackground(rl.RayWhite)
+
+	rl.DrawText("Level select", 450, 40, 50, rl.Black)
+
+	modeText := fmt.Sprintf("< %v >", levelSelectModeNames[levelSelectModes[lss.selectedMode]])
+	rl.DrawText(modeText, 500, 110, 30, rl.DarkGray)
+
+	if len(lss.maps) == 0 {
+		rl.DrawText(fmt.Sprintf("No maps found in %v", lss.mapsDir), 400, 180, 20, rl.Black)
+		return
+	}
+
+	rl.DrawText("Map", 300, 170, 20, rl.Gray)
+	rl.DrawText("Size", 650, 170, 20, rl.Gray)
+	rl.DrawText("Best score", 800, 170, 20, rl.Gray)
+
+	// Scroll so the selected map stays visible
+	first := 0
+	if lss.selectedItem >= levelSelectVisibleMaps {
+		first = lss.selectedItem - levelSelectVisibleMaps + 1
+	}
+
+	for i := first; i < len(lss.maps) && i < first+levelSelectVisibleMaps; i++ {
+		m := lss.maps[i]
+		y := int32(200 + (i-first)*30)
+		color := lss.ColorFromItem(i)
+
+		best := "-"
+		if score, ok := lss.scores.BestOf(m.Path); ok {
+			best = fmt.Sprintf("%v", score)
+		}
+
+		rl.DrawText(m.Name, 300, y, 20, color)
+		rl.DrawText(fmt.Sprintf("%vx%v", m.Width, m.Height), 650, y, 20, color)
+		rl.DrawText(best, 800, y, 20, color)
+	}
+}
+
+func (lss LevelSelectScene) ColorFromItem(item_index int) rl.Color {
+	if item_index == lss.selectedItem {
+		return rl.Green
+	} else {
+		return rl.Black
+	}
+}
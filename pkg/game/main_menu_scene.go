@@ -1,53 +1,12 @@
 package game
 
 import (
+	"example.com/rplat/pkg/sim"
 	rl "github.com/chunqian/go-raylib/raylib"
 )
 
-// Workaround to be able to call methods on a pointer on my interface
-type MainMenuSceneWrapper struct {
-	mms *MainMenuScene
-}
-
-func NewMainMenuSceneWrapper(sm *SceneManager) MainMenuSceneWrapper {
-	return MainMenuSceneWrapper{mms: NewMainMenuScene(sm)}
-}
-
-// Implement Scene interface
-func (mmsw MainMenuSceneWrapper) Init() {
-
-}
-
-func (mmsw MainMenuSceneWrapper) UpdateInputs() {
-	mmsw.mms.UpdateInputs()
-}
-
-func (mmsw MainMenuSceneWrapper) ClearInputs() {
-	mmsw.mms.ClearInputs()
-}
-
-func (mmsw MainMenuSceneWrapper) HandleEvents() {
-	mmsw.mms.HandleEvents()
-}
-
-func (mmsw MainMenuSceneWrapper) Update(dt float32) {
-	mmsw.mms.Update(dt)
-}
-
-func (mmsw MainMenuSceneWrapper) Draw(factor float64) {
-	mmsw.mms.Draw(factor)
-}
-
-func (mmsw MainMenuSceneWrapper) End() {
-	mmsw.mms.End()
-}
-
-func (mmsw MainMenuSceneWrapper) ShouldExit() bool {
-	return mmsw.mms.ShouldExit()
-}
-
 type MainMenuScene struct {
-	inputManager *MenuInputManager
+	inputManager *InputManager
 	selectedItem int
 	items        []string
 	sceneManager *SceneManager
@@ -57,7 +16,7 @@ type MainMenuScene struct {
 func NewMainMenuScene(sm *SceneManager) *MainMenuScene {
 	mms := &MainMenuScene{}
 
-	im := NewMenuInputManager()
+	im := NewInputManager(MenuContext, sm.Settings(), sm.Gamepad())
 	mms.inputManager = &im
 	mms.sceneManager = sm
 	mms.exit = false
@@ -65,50 +24,85 @@ func NewMainMenuScene(sm *SceneManager) *MainMenuScene {
 
 	mms.items = append(mms.items, "Tutorial")
 	mms.items = append(mms.items, "Random game")
+	mms.items = append(mms.items, "Level select")
+	mms.items = append(mms.items, "Settings")
 	mms.items = append(mms.items, "Exit")
 
 	return mms
 }
 
+func (mms *MainMenuScene) Init() {
+
+}
+
 func (mms *MainMenuScene) UpdateInputs() {
 	mms.inputManager.Update()
+
+	if mms.inputManager.MouseMoved() {
+		if item := mms.itemAt(mms.inputManager.MousePosition()); item >= 0 {
+			mms.selectedItem = item
+		}
+	}
+}
+
+func (mms MainMenuScene) itemAt(position sim.Vector2) int {
+	return menuItemAt(mms.items, 500, 100, 30, 20, position)
 }
 
 func (mms *MainMenuScene) ClearInputs() {
 	mms.inputManager.Clear()
 }
 
+func (mms *MainMenuScene) ResetInputs() {
+	mms.inputManager.Reset()
+}
+
 func (mms *MainMenuScene) End() {
 
 }
 
 func (mms *MainMenuScene) HandleEvents() {
-	for i := 0; i < len(mms.inputManager.events); i++ {
-		switch e := mms.inputManager.events[i]; e {
-		case "move_up":
-			if mms.selectedItem <= 0 {
-				mms.selectedItem = len(mms.items) - 1
-			} else {
-				mms.selectedItem -= 1
-			}
-		case "move_down":
-			if mms.selectedItem >= len(mms.items)-1 {
-				mms.selectedItem = 0
-			} else {
-				mms.selectedItem += 1
-			}
-		case "validate":
-			switch mms.selectedItem {
-			case 0:
-				mms.sceneManager.SwapScene("tutorial_game")
-			case 1:
-				mms.sceneManager.SwapScene("random_game")
-			case 2:
-				mms.exit = true
+	mms.sceneManager.Dispatch(mms.inputManager.events, ActionHandlers{
+		ActionMoveUp: func() {
+			mms.selectedItem = cycle(mms.selectedItem, -1, len(mms.items))
+		},
+		ActionMoveDown: func() {
+			mms.selectedItem = cycle(mms.selectedItem, 1, len(mms.items))
+		},
+		ActionValidate: mms.validate,
+		ActionClick: func() {
+			if item := mms.itemAt(mms.inputManager.MousePosition()); item >= 0 {
+				mms.selectedItem = item
+				mms.validate()
 			}
-		default:
-			// Unknown menu item
-		}
+		},
+		ActionMoveLeft:  nil,
+		ActionMoveRight: nil,
+		ActionBack:      nil,
+	})
+}
+
+// startGame plays the mode on the map given on the command line, game scenes
+// are kept and would otherwise play the map last picked in the level select.
+func (mms *MainMenuScene) startGame(mode string, transition Transition) {
+	if ms, ok := mms.sceneManager.Prepare(mode).(MapScene); ok {
+		ms.ResetMapPath()
+	}
+	mms.sceneManager.SwapSceneWith(mode, transition)
+}
+
+func (mms *MainMenuScene) validate() {
+	switch mms.selectedItem {
+	case 0:
+		mms.startGame("tutorial_game", NewFade())
+	case 1:
+		mms.startGame("random_game", NewWipe())
+	case 2:
+		mms.sceneManager.PushScene("level_select")
+	case 3:
+		mms.sceneManager.PushScene("settings")
+	case 4:
+		mms.exit = true
 	}
 }
 
@@ -121,12 +115,9 @@ func (mms MainMenuScene) ShouldExit() bool {
 }
 
 func (mms MainMenuScene) Draw(factor float64) {
-	rl.BeginDrawing()
-	defer rl.EndDrawing()
-
-	rl.DrawText(mms.items[0], 500, 100, 20, mms.ColorFromItem(0))
-	rl.DrawText(mms.items[1], 500, 130, 20, mms.ColorFromItem(1))
-	rl.DrawText(mms.items[2], 500, 160, 20, mms.ColorFromItem(2))
+	for i, item := range mms.items {
+		rl.DrawText(item, 500, int32(100+i*30), 20, mms.ColorFromItem(i))
+	}
 
 	rl.ClearBackground(rl.RayWhite)
 }
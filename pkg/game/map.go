@@ -1,21 +1,13 @@
 package game
 
 import (
-	"encoding/json"
-	"fmt"
-	rl "github.com/chunqian/go-raylib/raylib"
 	"io/ioutil"
-)
-
-type Property struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
-}
+	"path/filepath"
+	"strings"
 
-type Tile struct {
-	Index      int        `json:"index"`
-	Properties []Property `json:"properties"`
-}
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
 
 //
 //  Tileset
@@ -36,9 +28,12 @@ func NewTileset(path string, tileWidth, tileHeight int) Tileset {
 			rect := rl.Rectangle{X: float32(x * tileWidth), Y: float32(y * tileHeight), Width: float32(tileWidth), Height: float32(tileHeight)}
 			image := rl.ImageFromImage(tileset, rect)
 			tiles = append(tiles, rl.LoadTextureFromImage(image))
+			rl.UnloadImage(image)
 		}
 	}
 
+	rl.UnloadImage(tileset)
+
 	return Tileset{tiles: tiles}
 }
 
@@ -48,69 +43,26 @@ func (ts Tileset) Unload() {
 	}
 }
 
-//
-//  MapConfiguration
-//
-
-type MapConfiguration struct {
-	Width      int      `json:"width"`
-	Height     int      `json:"height"`
-	TileHeight int      `json:"tileHeight"`
-	TileWidth  int      `json:"tileWidth"`
-	Board      [][]Tile `json:"tiles"`
-}
-
-func NewMapConfiguration(path string) MapConfiguration {
-	var mc MapConfiguration
-
-	data, err := ioutil.ReadFile(path)
-	if err != nil {
-		fmt.Print(err)
-	}
-
-	err = json.Unmarshal(data, &mc)
-	if err != nil {
-		fmt.Println("error:", err)
-	}
-
-	return mc
-}
-
 //
 //  Map
 //
 
+const DefaultMapPath = "./assets/map.json"
+const DefaultTilesetPath = "./assets/tileset.png"
+const DefaultMapsDir = "./assets"
+
 type Map struct {
-	mc MapConfiguration
+	mc sim.MapConfiguration
 	ts Tileset
 
 	width      int
 	height     int
 	tileWidth  int
 	tileHeight int
-	board      [][]Tile
-	walls      []rl.Rectangle
+	board      [][]sim.Tile
 }
 
-func NewMap(mc MapConfiguration, ts Tileset) Map {
-	var walls []rl.Rectangle
-
-	for y := 0; y < len(mc.Board); y++ {
-		for x := 0; x < len(mc.Board[y]); x++ {
-			tileIndex := mc.Board[y][x].Index
-
-			// TODO: Handle properties
-			if tileIndex >= 0 {
-				walls = append(walls, rl.Rectangle{
-					X:      float32(x * mc.TileWidth),
-					Y:      float32(y * mc.TileHeight),
-					Width:  float32(mc.TileWidth),
-					Height: float32(mc.TileHeight),
-				})
-			}
-		}
-	}
-
+func NewMap(mc sim.MapConfiguration, ts Tileset) Map {
 	m := Map{
 		mc:         mc,
 		ts:         ts,
@@ -119,18 +71,66 @@ func NewMap(mc MapConfiguration, ts Tileset) Map {
 		tileWidth:  mc.TileWidth,
 		tileHeight: mc.TileHeight,
 		board:      mc.Board,
-		walls:      walls,
 	}
 
 	return m
 }
 
+// TilesetPath returns the path of the tileset used by the map read from
+// mapPath. The path stored in the map is relative to the map file.
+func TilesetPath(mapPath string, mc sim.MapConfiguration) string {
+	if mc.ImagePath == "" {
+		return DefaultTilesetPath
+	}
+
+	return filepath.Join(filepath.Dir(mapPath), mc.ImagePath)
+}
+
+// MapInfo describes a map found on disk.
+type MapInfo struct {
+	Name   string
+	Path   string
+	Width  int
+	Height int
+}
+
+// ScanMaps lists the ttme maps of a directory, other JSON files are skipped.
+func ScanMaps(dir string) ([]MapInfo, error) {
+	var maps []MapInfo
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		path := filepath.Join(dir, file.Name())
+		mc, err := sim.NewMapConfiguration(path)
+		if err != nil || len(mc.Board) == 0 {
+			continue
+		}
+
+		maps = append(maps, MapInfo{
+			Name:   strings.TrimSuffix(file.Name(), ".json"),
+			Path:   path,
+			Width:  mc.Width,
+			Height: mc.Height,
+		})
+	}
+
+	return maps, nil
+}
+
 func (m Map) Draw() {
 	for y := 0; y < len(m.board); y++ {
 		for x := 0; x < len(m.board[y]); x++ {
 			tileIndex := m.board[y][x].Index
 
-			if tileIndex >= 0 {
+			if tileIndex >= 0 && tileIndex < len(m.ts.tiles) {
 				rl.DrawTexture(m.ts.tiles[tileIndex], int32(x*m.tileWidth), int32(y*m.tileHeight), rl.White)
 			}
 		}
@@ -0,0 +1,35 @@
+package game
+
+import (
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// cycle moves a menu index by a step, wrapping around. An index of -1 goes
+// to the first choice.
+func cycle(index, step, length int) int {
+	if index < 0 || length == 0 {
+		return 0
+	}
+
+	return ((index+step)%length + length) % length
+}
+
+// menuItemAt gives the index of the item under a position, or -1, for a menu
+// drawn from (x, y) with one item every spacing pixels.
+func menuItemAt(items []string, x, y, spacing, fontSize int32, position sim.Vector2) int {
+	for i, item := range items {
+		rec := rl.Rectangle{
+			X:      float32(x),
+			Y:      float32(y + int32(i)*spacing),
+			Width:  float32(rl.MeasureText(item, fontSize)),
+			Height: float32(fontSize),
+		}
+
+		if rl.CheckCollisionPointRec(toRlVector2(position), rec) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,26 @@
+package game
+
+// Options are the launch settings of the game, usually coming from the command line.
+type Options struct {
+	Scene        string
+	MapPath      string
+	MapsDir      string // Scanned for maps by the level select scene
+	Seed         int64  // 0 means a new random seed for each round
+	ScreenWidth  int    // 0 keeps the size from the settings
+	ScreenHeight int
+	Fullscreen   bool // Forces fullscreen over the settings
+	Debug        bool // Forces the debug overlay over the settings
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Scene:        "main_menu",
+		MapPath:      DefaultMapPath,
+		MapsDir:      DefaultMapsDir,
+		Seed:         0,
+		ScreenWidth:  0,
+		ScreenHeight: 0,
+		Fullscreen:   false,
+		Debug:        false,
+	}
+}
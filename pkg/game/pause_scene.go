@@ -0,0 +1,134 @@
+package game
+
+import (
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// PauseScene is pushed over a game scene, which stays drawn but frozen until
+// the game is resumed.
+type PauseScene struct {
+	inputManager *InputManager
+	selectedItem int
+	items        []string
+	sceneManager *SceneManager
+	gameScene    string
+}
+
+func NewPauseScene(sm *SceneManager) *PauseScene {
+	ps := &PauseScene{}
+
+	im := NewInputManager(MenuContext, sm.Settings(), sm.Gamepad())
+	ps.inputManager = &im
+	ps.sceneManager = sm
+
+	ps.items = append(ps.items, "Resume")
+	ps.items = append(ps.items, "Restart")
+	if sm.IsRegistered("settings") {
+		ps.items = append(ps.items, "Settings")
+	}
+	ps.items = append(ps.items, "Main menu")
+
+	return ps
+}
+
+func (ps *PauseScene) Init() {
+	ps.selectedItem = 0
+	ps.gameScene = ps.sceneManager.SceneNameBelow()
+}
+
+func (ps *PauseScene) UpdateInputs() {
+	ps.inputManager.Update()
+
+	if ps.inputManager.MouseMoved() {
+		if item := ps.itemAt(ps.inputManager.MousePosition()); item >= 0 {
+			ps.selectedItem = item
+		}
+	}
+}
+
+func (ps PauseScene) itemAt(position sim.Vector2) int {
+	return menuItemAt(ps.items, 500, 180, 30, 20, position)
+}
+
+func (ps *PauseScene) ClearInputs() {
+	ps.inputManager.Clear()
+}
+
+func (ps *PauseScene) ResetInputs() {
+	ps.inputManager.Reset()
+}
+
+func (ps *PauseScene) End() {
+
+}
+
+func (ps *PauseScene) HandleEvents() {
+	ps.sceneManager.Dispatch(ps.inputManager.events, ActionHandlers{
+		ActionMoveUp: func() {
+			ps.selectedItem = cycle(ps.selectedItem, -1, len(ps.items))
+		},
+		ActionMoveDown: func() {
+			ps.selectedItem = cycle(ps.selectedItem, 1, len(ps.items))
+		},
+		ActionBack:     ps.sceneManager.PopScene,
+		ActionValidate: ps.validate,
+		ActionClick: func() {
+			if item := ps.itemAt(ps.inputManager.MousePosition()); item >= 0 {
+				ps.selectedItem = item
+				ps.validate()
+			}
+		},
+		ActionMoveLeft:  nil,
+		ActionMoveRight: nil,
+	})
+}
+
+func (ps *PauseScene) validate() {
+	switch ps.items[ps.selectedItem] {
+	case "Resume":
+		ps.sceneManager.PopScene()
+	case "Restart":
+		// Nothing to restart without a game below
+		if ps.gameScene != "" {
+			ps.sceneManager.SwapSceneWith(ps.gameScene, NewFade())
+		}
+	case "Settings":
+		ps.sceneManager.PushScene("settings")
+	case "Main menu":
+		ps.sceneManager.SwapSceneWith("main_menu", NewFade())
+	}
+}
+
+func (ps *PauseScene) Update(deltaTime float32) {
+
+}
+
+func (ps PauseScene) ShouldExit() bool {
+	return false
+}
+
+func (ps PauseScene) UpdateBelow() bool {
+	return false
+}
+
+func (ps PauseScene) DrawBelow() bool {
+	return true
+}
+
+func (ps PauseScene) Draw(factor float64) {
+	rl.DrawRectangle(0, 0, rl.GetScreenWidth(), rl.GetScreenHeight(), rl.Fade(rl.Black, 0.5))
+
+	rl.DrawText("Paused", 500, 100, 50, rl.White)
+	for i, item := range ps.items {
+		rl.DrawText(item, 500, int32(180+i*30), 20, ps.ColorFromItem(i))
+	}
+}
+
+func (ps PauseScene) ColorFromItem(item_index int) rl.Color {
+	if item_index == ps.selectedItem {
+		return rl.Green
+	} else {
+		return rl.White
+	}
+}
@@ -5,190 +5,271 @@ import (
 	"math/rand"
 	"time"
 
+	"example.com/rplat/pkg/sim"
 	rl "github.com/chunqian/go-raylib/raylib"
 )
 
-// Workaround to be able to call methods on a pointer on my interface
-type RandGameSceneWrapper struct {
-	rgs *RandomGameScene
-}
-
-func NewRandGameSceneWrapper(sm *SceneManager) RandGameSceneWrapper {
-	return RandGameSceneWrapper{rgs: NewRandomGameScene(sm)}
+type RandomGameScene struct {
+	world            *sim.World
+	level            Map
+	mapPath          string
+	defaultMapPath   string
+	mapConfiguration sim.MapConfiguration
+	tilesetPath      string
+	inputManager     *InputManager
+	roundTimer       sim.Timer
+	durationSeconds  int
+	starCount        int
+	result           RoundResult
+	score            int
+	sceneManager     *SceneManager
+	gameEnded        bool
+	paused           bool
+	aim              *sim.FixedInput
+	moveStrength     float32
+	seed             int64
+	rng              *rand.Rand
+	recording        *Replay
+	replay           *Replay
 }
 
-// Implement Scene interface
-func (rgsw RandGameSceneWrapper) Init() {
-	rgsw.rgs.Init()
-}
+func NewRandomGameScene(sm *SceneManager, options Options) *RandomGameScene {
+	rgs := &RandomGameScene{}
 
-func (rgsw RandGameSceneWrapper) UpdateInputs() {
-	rgsw.rgs.UpdateInputs()
-}
+	im := NewInputManager(GameplayContext, sm.Settings(), sm.Gamepad())
 
-func (rgsw RandGameSceneWrapper) ClearInputs() {
-	rgsw.rgs.ClearInputs()
-}
+	rgs.mapPath = options.MapPath
+	rgs.defaultMapPath = options.MapPath
+	rgs.seed = options.Seed
+	rgs.aim = &sim.FixedInput{}
+	rgs.inputManager = &im
+	rgs.inputManager.FollowHook(func() bool {
+		return rgs.world != nil && rgs.world.Player.HookLaunched()
+	})
+	rgs.durationSeconds = 30
+	rgs.starCount = 20
+	rgs.sceneManager = sm
 
-func (rgsw RandGameSceneWrapper) HandleEvents() {
-	rgsw.rgs.HandleEvents()
+	return rgs
 }
 
-func (rgsw RandGameSceneWrapper) Update(dt float32) {
-	rgsw.rgs.Update(dt)
-}
+// Load reads the level and checks its tileset can be loaded.
+func (rgs *RandomGameScene) Load() error {
+	assets := rgs.sceneManager.Assets()
 
-func (rgsw RandGameSceneWrapper) Draw(factor float64) {
-	rgsw.rgs.Draw(factor)
-}
+	mc, err := assets.Map(rgs.mapPath)
+	if err != nil {
+		return err
+	}
 
-func (rgsw RandGameSceneWrapper) End() {
-	rgsw.rgs.End()
-}
+	err = CheckAsset(TilesetPath(rgs.mapPath, mc))
+	if err != nil {
+		assets.ReleaseMap(rgs.mapPath)
+		return err
+	}
 
-func (rgsw RandGameSceneWrapper) ShouldExit() bool {
-	return rgsw.rgs.ShouldExit()
+	rgs.mapConfiguration = mc
+	return nil
 }
 
-type RandomGameScene struct {
-	player          *Player
-	level           Map
-	inputManager    *InputManager
-	elapsedSeconds  int
-	ticker          *time.Ticker
-	durationSeconds int
-	stars           []Star
-	score           int
-	sceneManager    *SceneManager
-	gameEnded       bool
-}
+func (rgs *RandomGameScene) Init() {
+	assets := rgs.sceneManager.Assets()
+	tilesetPath := TilesetPath(rgs.mapPath, rgs.mapConfiguration)
+
+	// Without its tileset the level is drawn without tiles, End must not give
+	// back a tileset that was never taken
+	tileset, err := assets.Tileset(tilesetPath, rgs.mapConfiguration.TileWidth, rgs.mapConfiguration.TileHeight)
+	if err != nil {
+		fmt.Println("error:", err)
+		rgs.tilesetPath = ""
+	} else {
+		rgs.tilesetPath = tilesetPath
+	}
 
-func NewRandomGameScene(sm *SceneManager) *RandomGameScene {
-	rgs := &RandomGameScene{}
+	rgs.level = NewMap(rgs.mapConfiguration, tileset)
+	rgs.world = sim.NewWorld(rgs.mapConfiguration, rgs.aim)
 
-	// Load level
-	mc := NewMapConfiguration("./assets/map.json")
-	tileset := NewTileset("./assets/tileset.png", mc.TileWidth, mc.TileHeight)
-	im := NewInputManager()
+	seed := rgs.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
 
-	rgs.level = NewMap(mc, tileset)
-	rgs.inputManager = &im
-	rgs.durationSeconds = 30
-	rgs.sceneManager = sm
+	rgs.recording = nil
+	hookMode := rgs.sceneManager.Settings().HookMode
 
-	return rgs
-}
+	if rgs.replay != nil {
+		rgs.replay.Rewind()
+		seed = rgs.replay.Seed
 
-func (rgs *RandomGameScene) Init() {
-	player := Player{
-		pos:          rl.Vector2{X: 32, Y: 32},
-		lastPos:      rl.Vector2{X: 20, Y: 20},
-		velocity:     rl.Vector2{X: 0, Y: 0},
-		lastVelocity: rl.Vector2{X: 0, Y: 0},
-		size:         rl.Vector2{X: 32, Y: 64},
-		canJump:      true,
-		color:        rl.Red,
+		// Older replays were all recorded with the pull hook
+		hookMode = sim.HookPull
+		if rgs.replay.HookMode != "" {
+			hookMode = rgs.replay.HookMode
+		}
+	} else {
+		rgs.recording = NewReplay(seed, rgs.mapPath, hookMode)
 	}
 
-	rgs.player = &player
+	rgs.rng = rand.New(rand.NewSource(seed))
+	rgs.world.HookMode = hookMode
+	rgs.world.Reset()
+	rgs.score = 0
 	rgs.gameEnded = false
-	rgs.ticker = time.NewTicker(1 * time.Second)
-	rgs.elapsedSeconds = 0
+	rgs.paused = false
+	rgs.roundTimer = sim.NewTimer(float64(rgs.durationSeconds))
+	rgs.roundTimer.Start()
 
-	for i := 0; i < 20; i++ {
+	for i := 0; i < rgs.starCount; i++ {
 		for !rgs.SpawnStar() {
 		}
 	}
-
-	go rgs.StartsCounter()
 }
 
 func (rgs *RandomGameScene) End() {
-	rgs.stars = nil
-}
-
-func (rgs *RandomGameScene) SpawnStar() bool {
-	s := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(s)
-	x := r.Intn(ScreenWidth)
-	y := r.Intn(ScreenHeight)
-
-	star := Star{rl.Vector2{X: float32(x), Y: float32(y)}}
+	assets := rgs.sceneManager.Assets()
+	assets.ReleaseMap(rgs.mapPath)
+	if rgs.tilesetPath != "" {
+		assets.ReleaseTileset(rgs.tilesetPath, rgs.mapConfiguration.TileWidth, rgs.mapConfiguration.TileHeight)
+		rgs.tilesetPath = ""
+	}
 
-	for _, wall := range rgs.level.walls {
-		if isColliding(star.Rectangle(), wall) {
-			return false
+	if rgs.recording != nil && len(rgs.recording.Frames) > 0 {
+		path, err := rgs.recording.Save()
+		if err != nil {
+			fmt.Println("error: could not save replay:", err)
+		} else {
+			fmt.Println("Replay saved to", path)
 		}
 	}
 
-	for _, exStar := range rgs.stars {
-		if isColliding(star.Rectangle(), exStar.Rectangle()) {
-			return false
-		}
+	rgs.world.Reset()
+	rgs.recording = nil
+	rgs.replay = nil
+}
+
+// PlayReplay makes the next run of the scene play the given replay back instead
+// of reading the player inputs.
+func (rgs *RandomGameScene) PlayReplay(replay *Replay) {
+	rgs.replay = replay
+
+	// Older replays don't know their map and keep the current one
+	if replay.MapPath != "" {
+		rgs.mapPath = replay.MapPath
 	}
+}
+
+// SetMapPath changes the map played from the next time the scene is entered.
+func (rgs *RandomGameScene) SetMapPath(path string) {
+	rgs.mapPath = path
+}
+
+func (rgs *RandomGameScene) ResetMapPath() {
+	rgs.mapPath = rgs.defaultMapPath
+}
+
+func (rgs *RandomGameScene) SpawnStar() bool {
+	x := rgs.rng.Intn(int(rgs.world.Width))
+	y := rgs.rng.Intn(int(rgs.world.Height))
 
-	rgs.stars = append(rgs.stars, star)
-	return true
+	return rgs.world.SpawnStar(sim.Vector2{X: float32(x), Y: float32(y)})
 }
 
 func (rgs *RandomGameScene) UpdateInputs() {
+	if rgs.replay != nil && !rgs.replay.Done() {
+		frame, _ := rgs.replay.Next()
+		rgs.inputManager.events = append(rgs.inputManager.events, frame.Events...)
+		rgs.aim.Aim = frame.Aim
+		rgs.moveStrength = frame.Move
+		return
+	}
+
 	rgs.inputManager.Update()
+	rgs.aim.Aim = rgs.inputManager.AimPosition(rgs.world.Player.Position())
+	rgs.moveStrength = rgs.inputManager.MoveStrength()
 }
 
 func (rgs *RandomGameScene) ClearInputs() {
 	rgs.inputManager.Clear()
 }
 
+func (rgs *RandomGameScene) ResetInputs() {
+	rgs.inputManager.Reset()
+}
+
 func (rgs *RandomGameScene) HandleEvents() {
-	for i := 0; i < len(rgs.inputManager.events); i++ {
-		if rgs.gameEnded && rgs.inputManager.events[i] == "validate" {
-			rgs.sceneManager.SwapScene("main_menu")
-		} else {
-			switch e := rgs.inputManager.events[i]; e {
-			case "pause":
-				Pause = !Pause
-			case "move_right":
-				rgs.player.MoveRight()
-			case "move_left":
-				rgs.player.MoveLeft()
-			case "jump":
-				rgs.player.Jump()
-			case "hook":
-				rgs.player.Hook()
-			case "stop_hook":
-				rgs.player.StopHook()
-			case "dash":
-				rgs.player.Dash()
-			case "portal":
-				rgs.player.FirePortal(rgs.level.walls)
-			default:
-				// Unknown event
-			}
+	player := rgs.world.Player
+	actions := rgs.inputManager.events
+
+	// Pausing takes the whole step, the other actions are dropped so the
+	// replay, which only keeps the steps the world made, plays the same
+	for _, action := range actions {
+		if action == ActionPause {
+			actions = []Action{ActionPause}
+			break
 		}
 	}
-}
 
-func (rgs *RandomGameScene) StartsCounter() {
-	for range rgs.ticker.C {
-		if !Pause {
-			rgs.elapsedSeconds++
+	rgs.sceneManager.Dispatch(actions, ActionHandlers{
+		ActionPause: func() {
+			rgs.paused = true
+			rgs.sceneManager.PushScene("pause")
+		},
+		ActionMoveRight: func() {
+			player.MoveRight(rgs.moveStrength)
+		},
+		ActionMoveLeft: func() {
+			player.MoveLeft(rgs.moveStrength)
+		},
+		ActionJump:     player.Jump,
+		ActionStopJump: player.ReleaseJump,
+		ActionHook:     player.Hook,
+		ActionStopHook: player.StopHook,
+		ActionReelIn:   player.ReelIn,
+		ActionReelOut:  player.ReelOut,
+		ActionDash:     player.Dash,
+		ActionPortal: func() {
+			player.FirePortal(rgs.world.Walls)
+		},
+		ActionHelp:     nil,
+		ActionQuit:     nil,
+		ActionValidate: nil,
+	})
+}
 
-			if rgs.elapsedSeconds >= rgs.durationSeconds {
-				rgs.EndGame(false)
-				return
-			}
-		}
-	}
+func (rgs RandomGameScene) elapsedSeconds() int {
+	return int(rgs.roundTimer.Elapsed())
 }
 
+// EndGame computes the round result and shows it. Completing the round
+// multiplies the score by the seconds left, always at least 1.
 func (rgs *RandomGameScene) EndGame(complete bool) {
-	if complete {
-		multiplier := rgs.durationSeconds - rgs.elapsedSeconds
-		rgs.score *= multiplier
+	remaining := rgs.durationSeconds - rgs.elapsedSeconds()
+	multiplier := 1
+	if complete && remaining > 1 {
+		multiplier = remaining
 	}
 
+	rgs.result = RoundResult{
+		MapPath:        rgs.mapPath,
+		StarsCollected: rgs.starCount - len(rgs.world.Stars),
+		StarCount:      rgs.starCount,
+		Complete:       complete,
+		TimeRemaining:  remaining,
+		Multiplier:     multiplier,
+		BaseScore:      rgs.score,
+		Score:          rgs.score * multiplier,
+		Abilities:      rgs.world.Player.Stats(),
+		Replay:         rgs.replay != nil,
+	}
+
+	rgs.score = rgs.result.Score
 	rgs.gameEnded = true
-	rgs.ticker.Stop()
+	rgs.roundTimer.Stop()
+	rgs.sceneManager.PushScene("results")
+}
+
+func (rgs RandomGameScene) Result() RoundResult {
+	return rgs.result
 }
 
 func (rgs RandomGameScene) ShouldExit() bool {
@@ -200,64 +281,50 @@ func (rgs *RandomGameScene) Update(deltaTime float32) {
 		return
 	}
 
-	if !Pause {
-		rgs.player.color = rl.Green
-		rgs.player.lastPos = rgs.player.pos
-		rgs.player.lastVelocity = rgs.player.velocity
+	// The scene is only updated when the pause menu does not cover it
+	rgs.paused = false
 
-		rgs.player.Update(deltaTime)
-		rgs.player.checkAndHandleCollisions(rgs.level.walls)
+	if rgs.roundTimer.Tick(float64(deltaTime)) {
+		rgs.EndGame(false)
+		return
+	}
 
-		starsToRemove := []int{}
-		for i, star := range rgs.stars {
-			if isColliding(rgs.player.Rectangle(), star.Rectangle()) {
-				rgs.score += 10
-				starsToRemove = append(starsToRemove, i)
-			}
-		}
+	collected := rgs.world.Step(deltaTime)
+	rgs.score += 10 * len(collected)
 
-		for _, j := range starsToRemove {
-			rgs.stars = RemoveIndexStar(rgs.stars, j)
-		}
+	if rgs.recording != nil {
+		rgs.recording.Record(rgs.inputManager.events, rgs.aim.Aim, rgs.moveStrength)
+	}
 
-		if len(rgs.stars) == 0 {
-			rgs.EndGame(true)
-		}
+	if len(rgs.world.Stars) == 0 {
+		rgs.EndGame(true)
 	}
 }
 
 func (rgs RandomGameScene) Draw(factor float64) {
-	rl.BeginDrawing()
-	defer rl.EndDrawing()
-
 	rl.ClearBackground(rl.RayWhite)
 
 	rgs.level.Draw()
-	rgs.player.Draw(factor)
-	for _, star := range rgs.stars {
-		star.Draw()
-	}
+	rgs.world.Draw(RaylibRenderer{}, factor)
 
-	timeText := fmt.Sprintf("Elapsed time: %v", rgs.elapsedSeconds)
+	timeText := fmt.Sprintf("Elapsed time: %v", rgs.elapsedSeconds())
 	rl.DrawText(timeText, 500, 20, 40, rl.Black)
 
-	if rgs.gameEnded {
-		timeText := fmt.Sprintf("Score: %v", rgs.score)
-		rl.DrawText(timeText, 500, 60, 40, rl.Black)
-
-		rl.DrawText("Press enter to go back to main menu", 350, 200, 30, rl.Black)
-	}
+	drawHud(rgs.world.Player)
 
 	if Debug {
-		if Pause {
-			rl.DrawRectangleV(rl.Vector2{X: rgs.player.lastPos.X, Y: rgs.player.lastPos.Y}, rgs.player.size, rl.Gray)
+		player := rgs.world.Player
+
+		if rgs.paused {
+			rl.DrawRectangleV(toRlVector2(player.LastPosition()), toRlVector2(player.Size()), rl.Gray)
 		}
 
-		posText := fmt.Sprintf("Position: %v - %v", rgs.player.pos.X, rgs.player.pos.Y)
-		lastPosText := fmt.Sprintf("Last position: %v - %v", rgs.player.lastPos.X, rgs.player.lastPos.Y)
-		velText := fmt.Sprintf("Velocity: %v - %v", rgs.player.velocity.X, rgs.player.velocity.Y)
-		lastVelText := fmt.Sprintf("Last Velocity: %v - %v", rgs.player.lastVelocity.X, rgs.player.lastVelocity.Y)
-		distText := fmt.Sprintf("Distance: %v", rl.Vector2Distance(rgs.player.lastPos, rgs.player.pos))
+		posText := fmt.Sprintf("Position: %v - %v", player.Position().X, player.Position().Y)
+		lastPosText := fmt.Sprintf("Last position: %v - %v", player.LastPosition().X, player.LastPosition().Y)
+		velText := fmt.Sprintf("Velocity: %v - %v", player.Velocity().X, player.Velocity().Y)
+		lastVelText := fmt.Sprintf("Last Velocity: %v - %v", player.LastVelocity().X, player.LastVelocity().Y)
+		distText := fmt.Sprintf("Distance: %v", sim.Vector2Distance(player.LastPosition(), player.Position()))
+		stateText := fmt.Sprintf("State: %v", player.State())
 
 		rl.DrawFPS(10, 10)
 		rl.DrawText(posText, 10, 50, 20, rl.Black)
@@ -265,5 +332,6 @@ func (rgs RandomGameScene) Draw(factor float64) {
 		rl.DrawText(velText, 10, 90, 20, rl.Black)
 		rl.DrawText(lastVelText, 10, 110, 20, rl.Black)
 		rl.DrawText(distText, 10, 130, 20, rl.Black)
+		rl.DrawText(stateText, 10, 150, 20, rl.Black)
 	}
 }
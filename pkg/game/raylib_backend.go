@@ -0,0 +1,52 @@
+package game
+
+import (
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// raylib implementation of the sim backend interfaces.
+
+// Clock gives the current time in seconds. The game loop reads it to know how
+// many fixed steps to run, the simulation itself only counts steps.
+type Clock interface {
+	Now() float64
+}
+
+type RaylibClock struct{}
+
+func (c RaylibClock) Now() float64 {
+	return rl.GetTime()
+}
+
+type RaylibInput struct{}
+
+func (i RaylibInput) AimPosition() sim.Vector2 {
+	return fromRlVector2(rl.GetMousePosition())
+}
+
+type RaylibRenderer struct{}
+
+func (r RaylibRenderer) DrawRectangle(rec sim.Rectangle, color sim.Color) {
+	rl.DrawRectangleRec(toRlRectangle(rec), toRlColor(color))
+}
+
+func (r RaylibRenderer) DrawLine(start, end sim.Vector2, thick float32, color sim.Color) {
+	rl.DrawLineEx(toRlVector2(start), toRlVector2(end), thick, toRlColor(color))
+}
+
+func toRlVector2(v sim.Vector2) rl.Vector2 {
+	return rl.Vector2{X: v.X, Y: v.Y}
+}
+
+func fromRlVector2(v rl.Vector2) sim.Vector2 {
+	return sim.Vector2{X: v.X, Y: v.Y}
+}
+
+func toRlRectangle(rec sim.Rectangle) rl.Rectangle {
+	return rl.Rectangle{X: rec.X, Y: rec.Y, Width: rec.Width, Height: rec.Height}
+}
+
+func toRlColor(c sim.Color) rl.Color {
+	return rl.Color{R: c.R, G: c.G, B: c.B, A: c.A}
+}
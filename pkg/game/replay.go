@@ -0,0 +1,100 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"example.com/rplat/pkg/sim"
+)
+
+const ReplayDirectory = "./replays"
+
+// ReplayFrame holds what the player did during one fixed simulation step.
+type ReplayFrame struct {
+	Events []Action    `json:"events"`
+	Aim    sim.Vector2 `json:"aim"`
+	// Movement strength, older replays without it moved at full speed
+	Move float32 `json:"move,omitempty"`
+}
+
+// Replay is everything needed to play a random game run back frame for frame.
+type Replay struct {
+	Seed     int64         `json:"seed"`
+	MapPath  string        `json:"mapPath"`
+	HookMode sim.HookMode  `json:"hookMode"`
+	Frames   []ReplayFrame `json:"frames"`
+
+	cursor int
+}
+
+func NewReplay(seed int64, mapPath string, hookMode sim.HookMode) *Replay {
+	return &Replay{Seed: seed, MapPath: mapPath, HookMode: hookMode}
+}
+
+func LoadReplay(path string) (*Replay, error) {
+	var r Replay
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replay %s: %v", path, err)
+	}
+
+	return &r, nil
+}
+
+func (r *Replay) Record(events []Action, aim sim.Vector2, move float32) {
+	frame := ReplayFrame{Events: make([]Action, len(events)), Aim: aim, Move: move}
+	copy(frame.Events, events)
+
+	r.Frames = append(r.Frames, frame)
+}
+
+// Next returns the next recorded frame, the boolean is false once every frame
+// has been played.
+func (r *Replay) Next() (ReplayFrame, bool) {
+	if r.Done() {
+		return ReplayFrame{}, false
+	}
+
+	frame := r.Frames[r.cursor]
+	r.cursor++
+
+	if frame.Move == 0 {
+		frame.Move = 1
+	}
+
+	return frame, true
+}
+
+func (r Replay) Done() bool {
+	return r.cursor >= len(r.Frames)
+}
+
+func (r *Replay) Rewind() {
+	r.cursor = 0
+}
+
+// Save writes the replay into ReplayDirectory and returns the file path.
+func (r Replay) Save() (string, error) {
+	err := os.MkdirAll(ReplayDirectory, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(ReplayDirectory, fmt.Sprintf("replay-%v.json", time.Now().Unix()))
+	return path, ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,124 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"example.com/rplat/pkg/sim"
+)
+
+func TestReplayRecordCopiesEvents(t *testing.T) {
+	events := []Action{ActionMoveLeft, ActionJump}
+
+	r := NewReplay(1, "map.json", sim.HookPull)
+	r.Record(events, sim.Vector2{X: 1, Y: 2}, 1)
+	events[0] = ActionMoveRight
+
+	frame, _ := r.Next()
+	if !reflect.DeepEqual(frame.Events, []Action{ActionMoveLeft, ActionJump}) {
+		t.Errorf("recorded events %v changed with the input manager events", frame.Events)
+	}
+}
+
+func TestReplayNext(t *testing.T) {
+	frames := []ReplayFrame{
+		{Events: []Action{}, Aim: sim.Vector2{X: 0, Y: 0}, Move: 1},
+		{Events: []Action{ActionJump}, Aim: sim.Vector2{X: 10, Y: 20}, Move: 1},
+		{Events: []Action{ActionMoveLeft, ActionDash}, Aim: sim.Vector2{X: 30, Y: 40}, Move: 0.5},
+	}
+
+	r := NewReplay(42, "map.json", sim.HookPull)
+	for _, frame := range frames {
+		r.Record(frame.Events, frame.Aim, frame.Move)
+	}
+
+	tests := []struct {
+		name string
+		want []ReplayFrame
+	}{
+		{name: "first play", want: frames},
+		{name: "after rewind", want: frames},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.Rewind()
+
+			for i, want := range tt.want {
+				if r.Done() {
+					t.Fatalf("replay done after %v frames, want %v", i, len(tt.want))
+				}
+
+				frame, ok := r.Next()
+				if !ok || !reflect.DeepEqual(frame, want) {
+					t.Errorf("frame %v = %v, %v, want %v, true", i, frame, ok, want)
+				}
+			}
+
+			if _, ok := r.Next(); ok || !r.Done() {
+				t.Error("replay goes on past its last frame")
+			}
+		})
+	}
+}
+
+func TestReplayNextWithoutMove(t *testing.T) {
+	// Replays recorded before analog movement have no move strength
+	var r Replay
+	if err := json.Unmarshal([]byte(`{"seed": 1, "frames": [{"events": ["move_right"]}]}`), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	frame, ok := r.Next()
+	if !ok || frame.Move != 1 {
+		t.Errorf("frame = %v, %v, want a move strength of 1", frame, ok)
+	}
+}
+
+func TestLoadReplay(t *testing.T) {
+	recorded := NewReplay(7, "map.json", sim.HookRope)
+	recorded.Record([]Action{ActionJump}, sim.Vector2{X: 5, Y: 6}, 0.5)
+	data, err := json.Marshal(recorded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		want    *Replay
+		wantErr bool
+	}{
+		{name: "recorded replay", data: data, want: recorded},
+		{name: "not a replay", data: []byte("frames"), wantErr: true},
+		{name: "unknown action", data: []byte(`{"seed": 7, "frames": [{"events": ["fly"]}]}`), wantErr: true},
+	}
+
+	dir, err := ioutil.TempDir("", "replays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("replay-%v.json", i))
+			if err := ioutil.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			r, err := LoadReplay(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadReplay error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(r, tt.want) {
+				t.Errorf("LoadReplay = %v, want %v", r, tt.want)
+			}
+		})
+	}
+}
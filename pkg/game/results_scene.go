@@ -0,0 +1,208 @@
+package game
+
+import (
+	"fmt"
+
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+// RoundResult sums up a finished round.
+type RoundResult struct {
+	MapPath        string
+	StarsCollected int
+	StarCount      int
+	Complete       bool
+	TimeRemaining  int
+	Multiplier     int
+	BaseScore      int
+	Score          int
+	Abilities      sim.AbilityStats
+	// Played back from a replay file, the score is not a personal best
+	Replay bool
+}
+
+// ResultProvider is implemented by game scenes that push the results scene
+// when their round ends.
+type ResultProvider interface {
+	Result() RoundResult
+}
+
+// ResultsScene is pushed over a finished game scene and shows the result of
+// the round it provides.
+type ResultsScene struct {
+	inputManager *InputManager
+	selectedItem int
+	items        []string
+	sceneManager *SceneManager
+	gameScene    string
+	result       RoundResult
+	previousBest int
+	hasBest      bool
+	newBest      bool
+}
+
+func NewResultsScene(sm *SceneManager) *ResultsScene {
+	rs := &ResultsScene{}
+
+	im := NewInputManager(MenuContext, sm.Settings(), sm.Gamepad())
+	rs.inputManager = &im
+	rs.sceneManager = sm
+
+	rs.items = append(rs.items, "Retry")
+	rs.items = append(rs.items, "Main menu")
+
+	return rs
+}
+
+func (rs *ResultsScene) Init() {
+	rs.selectedItem = 0
+	rs.gameScene = rs.sceneManager.SceneNameBelow()
+
+	if provider, ok := rs.sceneManager.SceneBelow().(ResultProvider); ok {
+		rs.result = provider.Result()
+	}
+
+	scores, err := LoadScores()
+	if err != nil {
+		fmt.Println("error: could not load scores:", err)
+	}
+
+	rs.previousBest, rs.hasBest = scores.BestOf(rs.result.MapPath)
+	rs.newBest = false
+
+	// Replays can come from anyone, and a file that failed to load must not be
+	// overwritten with what could be read of it
+	if rs.result.Replay || err != nil {
+		return
+	}
+
+	rs.newBest = scores.Submit(rs.result.MapPath, rs.result.Score)
+
+	if rs.newBest {
+		err = scores.Save()
+		if err != nil {
+			fmt.Println("error: could not save scores:", err)
+		}
+	}
+}
+
+func (rs *ResultsScene) UpdateInputs() {
+	rs.inputManager.Update()
+
+	if rs.inputManager.MouseMoved() {
+		if item := rs.itemAt(rs.inputManager.MousePosition()); item >= 0 {
+			rs.selectedItem = item
+		}
+	}
+}
+
+func (rs ResultsScene) itemAt(position sim.Vector2) int {
+	return menuItemAt(rs.items, 500, 450, 30, 20, position)
+}
+
+func (rs *ResultsScene) ClearInputs() {
+	rs.inputManager.Clear()
+}
+
+func (rs *ResultsScene) ResetInputs() {
+	rs.inputManager.Reset()
+}
+
+func (rs *ResultsScene) End() {
+
+}
+
+func (rs *ResultsScene) HandleEvents() {
+	rs.sceneManager.Dispatch(rs.inputManager.events, ActionHandlers{
+		ActionMoveUp: func() {
+			rs.selectedItem = cycle(rs.selectedItem, -1, len(rs.items))
+		},
+		ActionMoveDown: func() {
+			rs.selectedItem = cycle(rs.selectedItem, 1, len(rs.items))
+		},
+		ActionValidate: rs.validate,
+		ActionClick: func() {
+			if item := rs.itemAt(rs.inputManager.MousePosition()); item >= 0 {
+				rs.selectedItem = item
+				rs.validate()
+			}
+		},
+		ActionMoveLeft:  nil,
+		ActionMoveRight: nil,
+		ActionBack:      nil,
+	})
+}
+
+func (rs *ResultsScene) validate() {
+	switch rs.selectedItem {
+	case 0:
+		// Nothing to retry without a game below
+		if rs.gameScene != "" {
+			rs.sceneManager.SwapSceneWith(rs.gameScene, NewFade())
+		}
+	case 1:
+		rs.sceneManager.SwapSceneWith("main_menu", NewCrossfade())
+	}
+}
+
+func (rs *ResultsScene) Update(deltaTime float32) {
+
+}
+
+func (rs ResultsScene) ShouldExit() bool {
+	return false
+}
+
+func (rs ResultsScene) UpdateBelow() bool {
+	return false
+}
+
+func (rs ResultsScene) DrawBelow() bool {
+	return false
+}
+
+func (rs ResultsScene) Draw(factor float64) {
+	rl.ClearBackground(rl.RayWhite)
+
+	title := "Time is up !"
+	if rs.result.Complete {
+		title = "All stars collected !"
+	}
+	rl.DrawText(title, 400, 60, 50, rl.Black)
+
+	abilities := rs.result.Abilities
+	lines := []string{
+		fmt.Sprintf("Stars collected: %v / %v", rs.result.StarsCollected, rs.result.StarCount),
+		fmt.Sprintf("Time remaining: %vs", rs.result.TimeRemaining),
+		fmt.Sprintf("Multiplier: x%v", rs.result.Multiplier),
+		fmt.Sprintf("Score: %v x %v = %v", rs.result.BaseScore, rs.result.Multiplier, rs.result.Score),
+		fmt.Sprintf("Abilities used: %v jumps, %v dashes, %v hooks, %v portals", abilities.Jumps, abilities.Dashes, abilities.Hooks, abilities.Portals),
+	}
+
+	if rs.result.Replay {
+		lines = append(lines, "Replay, the personal best is left unchanged")
+	} else if rs.newBest && rs.hasBest {
+		lines = append(lines, fmt.Sprintf("New personal best ! (previous: %v)", rs.previousBest))
+	} else if rs.newBest {
+		lines = append(lines, "New personal best !")
+	} else {
+		lines = append(lines, fmt.Sprintf("Personal best: %v", rs.previousBest))
+	}
+
+	for i, line := range lines {
+		rl.DrawText(line, 300, int32(150+i*40), 30, rl.Black)
+	}
+
+	for i, item := range rs.items {
+		rl.DrawText(item, 500, int32(450+i*30), 20, rs.ColorFromItem(i))
+	}
+}
+
+func (rs ResultsScene) ColorFromItem(item_index int) rl.Color {
+	if item_index == rs.selectedItem {
+		return rl.Green
+	} else {
+		return rl.Black
+	}
+}
@@ -1,5 +1,12 @@
 package game
 
+import (
+	"fmt"
+
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
 type Scene interface {
 	Init()
 	UpdateInputs()
@@ -11,20 +18,317 @@ type Scene interface {
 	ShouldExit() bool
 }
 
+// OverlayScene can be implemented by a scene pushed over other scenes to let
+// the scenes beneath it keep updating or drawing. A pushed scene that does not
+// implement it freezes and hides everything beneath it.
+type OverlayScene interface {
+	UpdateBelow() bool
+	DrawBelow() bool
+}
+
+// PollingScene can be implemented by scenes reading inputs raylib only keeps for
+// a frame, like the queue of pressed keys. PollInputs is called once per frame
+// before the fixed steps, which can run several times or not at all.
+type PollingScene interface {
+	PollInputs()
+}
+
+// SceneFactory builds a scene, it is called by the SceneManager the first time
+// the scene is entered.
+type SceneFactory func(sm *SceneManager) Scene
+
+type sceneEntry struct {
+	factory  SceneFactory
+	recreate bool
+	prepared bool
+	scene    Scene
+}
+
+// LoadingScene can be implemented by scenes with heavy initialization work.
+// Load runs in its own goroutine behind a loading screen before Init is called,
+// so it must not touch the GPU. When it fails, the fallback scene is entered
+// instead.
+type LoadingScene interface {
+	Load() error
+}
+
+// InputResetter can be implemented by scenes following input states from one
+// step to the next. ResetInputs is called when the scene gets the inputs,
+// so keys held meanwhile are not seen as new presses.
+type InputResetter interface {
+	ResetInputs()
+}
+
+const (
+	transitionNone = iota
+	transitionOut
+	transitionLoading
+	transitionIn
+)
+
+// SceneManager keeps a stack of scenes. Only the top scene reads inputs, the
+// scenes beneath it are updated and drawn as long as the scenes above allow it.
 type SceneManager struct {
-	scenes           map[string]Scene
-	currentSceneName string
+	scenes   map[string]*sceneEntry
+	stack    []string
+	assets   *AssetManager
+	settings *Settings
+	gamepad  *Gamepad
+	// Scene that got the inputs on the last step
+	inputScene Scene
+	// Entered when a scene fails to load, it should not need loading itself
+	fallbackScene string
+	// Incremented on every stack change so a loop over the stack can tell a
+	// scene swapped or pushed another one
+	version int
+
+	transition      Transition
+	transitionState int
+	transitionScene string
+	transitionTimer sim.Timer
+	loadingTime     float64
+	loaded          chan struct{}
+	loadError       error
+}
+
+func NewSceneManager(assets *AssetManager, settings *Settings, fallbackScene string) SceneManager {
+	return SceneManager{
+		scenes:        make(map[string]*sceneEntry),
+		assets:        assets,
+		settings:      settings,
+		gamepad:       NewGamepad(),
+		fallbackScene: fallbackScene,
+	}
+}
+
+// Assets gives the asset manager shared by every scene.
+func (sm SceneManager) Assets() *AssetManager {
+	return sm.assets
+}
+
+// Settings gives the user preferences shared by every scene.
+func (sm SceneManager) Settings() *Settings {
+	return sm.settings
+}
+
+// Gamepad gives the gamepad in use, shared by every scene.
+func (sm SceneManager) Gamepad() *Gamepad {
+	return sm.gamepad
+}
+
+// Register adds a scene that is built the first time it is entered and kept
+// for the next entries.
+func (sm *SceneManager) Register(name string, factory SceneFactory) {
+	sm.scenes[name] = &sceneEntry{factory: factory}
 }
 
+// RegisterRecreated adds a scene that is built again each time it is entered.
+func (sm *SceneManager) RegisterRecreated(name string, factory SceneFactory) {
+	sm.scenes[name] = &sceneEntry{factory: factory, recreate: true}
+}
+
+func (sm SceneManager) IsRegistered(name string) bool {
+	_, ok := sm.scenes[name]
+	return ok
+}
+
+// enter builds the scene if needed before it goes on the stack.
+func (sm *SceneManager) enter(name string) {
+	entry, ok := sm.scenes[name]
+	if !ok {
+		panic("game: unknown scene " + name)
+	}
+
+	if entry.scene == nil || (entry.recreate && !entry.prepared) {
+		entry.scene = entry.factory(sm)
+	}
+	entry.prepared = false
+}
+
+// Prepare builds the scene if needed without entering it, so it can be
+// configured before being swapped or pushed.
+func (sm *SceneManager) Prepare(name string) Scene {
+	sm.enter(name)
+	sm.scenes[name].prepared = true
+	return sm.scene(name)
+}
+
+func (sm SceneManager) scene(name string) Scene {
+	return sm.scenes[name].scene
+}
+
+// SwapScene ends every scene of the stack and replaces them with the given one
+// without any transition.
 func (sm *SceneManager) SwapScene(scene string) {
+	sm.SwapSceneWith(scene, Cut{})
+}
+
+// SwapSceneWith hides the current scenes with the transition, replaces them
+// with the given one and reveals it.
+func (sm *SceneManager) SwapSceneWith(scene string, transition Transition) {
+	if sm.transitionState == transitionOut || sm.transitionState == transitionLoading {
+		return
+	}
+
+	if sm.settings.ReduceMotion {
+		transition = Cut{}
+	}
+
+	sm.finishTransition()
+	sm.transition = transition
+	sm.transitionScene = scene
+
+	out, _ := transition.Durations()
+	if out > 0 {
+		sm.transitionState = transitionOut
+		sm.transitionTimer = sim.NewTimer(out)
+		sm.transitionTimer.Start()
+	} else {
+		sm.swap()
+	}
+}
+
+func (sm *SceneManager) swap() {
+	if snapshot, ok := sm.transition.(SnapshotTransition); ok {
+		snapshot.Capture(func() { sm.drawStack(1) })
+	}
+
+	oldStack := sm.stack
+	sm.stack = []string{sm.transitionScene}
+	sm.version++
+
+	for i := len(oldStack) - 1; i >= 0; i-- {
+		sm.scene(oldStack[i]).End()
+	}
+	sm.enter(sm.transitionScene)
+
+	if loading, ok := sm.CurrentScene().(LoadingScene); ok {
+		sm.transitionState = transitionLoading
+		sm.loadingTime = 0
+		sm.loadError = nil
+		sm.loaded = make(chan struct{})
+
+		go func(done chan struct{}) {
+			sm.loadError = loading.Load()
+			close(done)
+		}(sm.loaded)
+	} else {
+		sm.reveal()
+	}
+}
+
+func (sm *SceneManager) reveal() {
+	sm.CurrentScene().Init()
+	// The new scene took its references, anything else can go
+	sm.assets.Collect()
+
+	_, in := sm.transition.Durations()
+	if in > 0 {
+		sm.transitionState = transitionIn
+		sm.transitionTimer = sim.NewTimer(in)
+		sm.transitionTimer.Start()
+	} else {
+		sm.finishTransition()
+	}
+}
+
+func (sm *SceneManager) finishTransition() {
+	if snapshot, ok := sm.transition.(SnapshotTransition); ok {
+		snapshot.Release()
+	}
+
+	sm.transition = nil
+	sm.transitionState = transitionNone
+}
+
+// updateTransition returns true while the scenes must stay frozen.
+func (sm *SceneManager) updateTransition(dt float32) bool {
+	switch sm.transitionState {
+	case transitionOut:
+		if sm.transitionTimer.Tick(float64(dt)) {
+			sm.swap()
+		}
+		return true
+	case transitionLoading:
+		sm.loadingTime += float64(dt)
+
+		select {
+		case <-sm.loaded:
+			if sm.loadError != nil {
+				fmt.Println("error: could not load scene", sm.CurrentSceneName()+":", sm.loadError)
+
+				sm.stack = []string{sm.fallbackScene}
+				sm.version++
+				sm.enter(sm.fallbackScene)
+			}
+
+			sm.reveal()
+		default:
+		}
+		return true
+	case transitionIn:
+		if sm.transitionTimer.Tick(float64(dt)) {
+			sm.finishTransition()
+		}
+	}
+
+	return false
+}
+
+func (sm SceneManager) inputsFrozen() bool {
+	return sm.transitionState == transitionOut || sm.transitionState == transitionLoading
+}
+
+// PushScene puts a scene over the current one without ending it.
+func (sm *SceneManager) PushScene(scene string) {
+	sm.enter(scene)
+	sm.stack = append(sm.stack, scene)
+	sm.version++
+
+	sm.CurrentScene().Init()
+}
+
+// PopScene ends the top scene and gives the control back to the scene beneath
+// it, which is not initialized again.
+func (sm *SceneManager) PopScene() {
+	if len(sm.stack) <= 1 {
+		return
+	}
+
 	oldScene := sm.CurrentScene()
-	sm.currentSceneName = scene
+	sm.stack = sm.stack[:len(sm.stack)-1]
+	sm.version++
+
 	oldScene.End()
-	sm.CurrentScene().Init()
+	sm.assets.Collect()
+}
+
+func (sm SceneManager) PollInputs() {
+	if sm.inputsFrozen() {
+		return
+	}
+
+	if scene, ok := sm.CurrentScene().(PollingScene); ok {
+		scene.PollInputs()
+	}
 }
 
-func (sm SceneManager) UpdateInputs() {
-	sm.CurrentScene().UpdateInputs()
+func (sm *SceneManager) UpdateInputs() {
+	sm.gamepad.Update()
+
+	if sm.inputsFrozen() {
+		return
+	}
+
+	scene := sm.CurrentScene()
+	if scene != sm.inputScene {
+		if resetter, ok := scene.(InputResetter); ok {
+			resetter.ResetInputs()
+		}
+		sm.inputScene = scene
+	}
+
+	scene.UpdateInputs()
 }
 
 func (sm SceneManager) ClearInputs() {
@@ -32,21 +336,97 @@ func (sm SceneManager) ClearInputs() {
 }
 
 func (sm SceneManager) HandleEvents() {
+	if sm.inputsFrozen() {
+		return
+	}
+
 	sm.CurrentScene().HandleEvents()
 }
 
-func (sm SceneManager) Update(dt float32) {
-	sm.CurrentScene().Update(dt)
+func (sm *SceneManager) Update(dt float32) {
+	if sm.updateTransition(dt) {
+		return
+	}
+
+	version := sm.version
+
+	for i := len(sm.stack) - 1; i >= 0; i-- {
+		scene := sm.scene(sm.stack[i])
+		scene.Update(dt)
+
+		if sm.version != version || !updatesBelow(scene) {
+			return
+		}
+	}
 }
 
 func (sm SceneManager) Draw(factor float64) {
-	sm.CurrentScene().Draw(factor)
+	rl.BeginDrawing()
+	defer rl.EndDrawing()
+
+	switch sm.transitionState {
+	case transitionLoading:
+		drawLoadingScreen(sm.loadingTime)
+	case transitionOut:
+		sm.drawStack(factor)
+		sm.transition.Draw(TransitionOut, sm.transitionTimer.Progress())
+	case transitionIn:
+		sm.drawStack(factor)
+		sm.transition.Draw(TransitionIn, sm.transitionTimer.Progress())
+	default:
+		sm.drawStack(factor)
+	}
+}
+
+func (sm SceneManager) drawStack(factor float64) {
+	bottom := len(sm.stack) - 1
+	for bottom > 0 && drawsBelow(sm.scene(sm.stack[bottom])) {
+		bottom--
+	}
+
+	for i := bottom; i < len(sm.stack); i++ {
+		sm.scene(sm.stack[i]).Draw(factor)
+	}
 }
 
 func (sm SceneManager) CurrentScene() Scene {
-	return sm.scenes[sm.currentSceneName]
+	return sm.scene(sm.CurrentSceneName())
+}
+
+func (sm SceneManager) CurrentSceneName() string {
+	return sm.stack[len(sm.stack)-1]
+}
+
+// SceneBelow returns the scene right beneath the current one, or nil if there is none.
+func (sm SceneManager) SceneBelow() Scene {
+	name := sm.SceneNameBelow()
+	if name == "" {
+		return nil
+	}
+
+	return sm.scene(name)
+}
+
+// SceneNameBelow returns the name of the scene right beneath the current one,
+// or an empty string if there is none.
+func (sm SceneManager) SceneNameBelow() string {
+	if len(sm.stack) < 2 {
+		return ""
+	}
+
+	return sm.stack[len(sm.stack)-2]
 }
 
 func (sm SceneManager) ShouldExit() bool {
 	return sm.CurrentScene().ShouldExit()
 }
+
+func updatesBelow(scene Scene) bool {
+	overlay, ok := scene.(OverlayScene)
+	return ok && overlay.UpdateBelow()
+}
+
+func drawsBelow(scene Scene) bool {
+	overlay, ok := scene.(OverlayScene)
+	return ok && overlay.DrawBelow()
+}
@@ -0,0 +1,71 @@
+package game
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const ScoresFile = "scores.json"
+
+// Scores keeps the personal best of each map, keyed by map path.
+type Scores struct {
+	Best map[string]int `json:"best"`
+}
+
+// LoadScores reads the personal bests, a missing file gives empty scores.
+func LoadScores() (Scores, error) {
+	scores := Scores{Best: make(map[string]int)}
+
+	path, err := UserFilePath(ScoresFile)
+	if err != nil {
+		return scores, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return scores, nil
+	} else if err != nil {
+		return scores, err
+	}
+
+	err = json.Unmarshal(data, &scores)
+	if scores.Best == nil {
+		scores.Best = make(map[string]int)
+	}
+
+	return scores, err
+}
+
+// BestOf gives the personal best of a map, the boolean is false if the map
+// was never played.
+func (s Scores) BestOf(mapPath string) (int, bool) {
+	best, ok := s.Best[filepath.Clean(mapPath)]
+	return best, ok
+}
+
+// Submit records a score and returns true if it beats the personal best of the map.
+func (s *Scores) Submit(mapPath string, score int) bool {
+	best, ok := s.BestOf(mapPath)
+	if ok && score <= best {
+		return false
+	}
+
+	s.Best[filepath.Clean(mapPath)] = score
+	return true
+}
+
+func (s Scores) Save() error {
+	path, err := UserFilePath(ScoresFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,63 @@
+package game
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestScoresSubmit(t *testing.T) {
+	tests := []struct {
+		name     string
+		mapPath  string
+		score    int
+		wantBest bool
+		want     int
+	}{
+		{name: "first score of a map", mapPath: "other.json", score: 3, wantBest: true, want: 3},
+		{name: "better score", mapPath: "map.json", score: 12, wantBest: true, want: 12},
+		{name: "same score", mapPath: "map.json", score: 10, wantBest: false, want: 10},
+		{name: "worse score", mapPath: "map.json", score: 4, wantBest: false, want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scores := Scores{Best: map[string]int{"map.json": 10}}
+
+			if got := scores.Submit(tt.mapPath, tt.score); got != tt.wantBest {
+				t.Errorf("Submit(%v) = %v, want %v", tt.score, got, tt.wantBest)
+			}
+			if scores.Best[tt.mapPath] != tt.want {
+				t.Errorf("best of %v = %v, want %v", tt.mapPath, scores.Best[tt.mapPath], tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadScores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rplat-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// UserConfigDir follows XDG_CONFIG_HOME on linux
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	os.Setenv("XDG_CONFIG_HOME", dir)
+
+	scores, err := LoadScores()
+	if err != nil || len(scores.Best) != 0 {
+		t.Fatalf("LoadScores without a file = %v, %v, want no scores", scores, err)
+	}
+
+	scores.Submit("map.json", 8)
+	if err := scores.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadScores()
+	if err != nil || !reflect.DeepEqual(loaded, scores) {
+		t.Errorf("LoadScores = %v, %v, want %v", loaded, err, scores)
+	}
+}
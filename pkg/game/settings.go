@@ -0,0 +1,153 @@
+package game
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+const SettingsFile = "settings.json"
+
+// Settings are the user preferences changed in the settings scene and kept
+// in the user config directory between runs.
+type Settings struct {
+	KeyBindings     KeyBindings `json:"keyBindings"`
+	MenuKeyBindings KeyBindings `json:"menuKeyBindings"`
+	ScreenWidth     int         `json:"screenWidth"`
+	ScreenHeight    int         `json:"screenHeight"`
+	Fullscreen      bool        `json:"fullscreen"`
+	TargetFPS       int         `json:"targetFPS"`
+	Volume          float32     `json:"volume"`
+	Debug           bool        `json:"debug"`
+	// Replaces scene transitions with cuts
+	ReduceMotion bool `json:"reduceMotion"`
+	// Pressing hook once launches it and pressing it again releases it
+	ToggleHook bool `json:"toggleHook"`
+	// Pull toward the hook or swing on a rope, from the next round on
+	HookMode sim.HookMode `json:"hookMode"`
+
+	// Command line options win over the saved values for this run only
+	overrides Options
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		KeyBindings:     DefaultKeyBindings(),
+		MenuKeyBindings: DefaultMenuKeyBindings(),
+		ScreenWidth:     ScreenWidth,
+		ScreenHeight:    ScreenHeight,
+		Fullscreen:      false,
+		TargetFPS:       FPS,
+		Volume:          1,
+		Debug:           false,
+		ReduceMotion:    false,
+		ToggleHook:      false,
+		HookMode:        sim.HookPull,
+	}
+}
+
+// LoadSettings reads the user preferences over the default ones, a missing
+// file gives the default settings.
+func LoadSettings() (Settings, error) {
+	settings := DefaultSettings()
+
+	path, err := UserFilePath(SettingsFile)
+	if err != nil {
+		return settings, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	} else if err != nil {
+		return settings, err
+	}
+
+	err = json.Unmarshal(data, &settings)
+	if err != nil {
+		return DefaultSettings(), err
+	}
+
+	settings.KeyBindings = withDefaults(settings.KeyBindings, DefaultKeyBindings())
+	settings.MenuKeyBindings = withDefaults(settings.MenuKeyBindings, DefaultMenuKeyBindings())
+
+	return settings, nil
+}
+
+// withDefaults gives the default keys to the actions missing from an older file.
+func withDefaults(bindings, defaults KeyBindings) KeyBindings {
+	if bindings == nil {
+		bindings = make(KeyBindings)
+	}
+
+	for action, keys := range defaults {
+		if _, ok := bindings[action]; !ok {
+			bindings[action] = keys
+		}
+	}
+
+	return bindings
+}
+
+// Bindings gives the key bindings of an input context.
+func (s Settings) Bindings(context InputContext) KeyBindings {
+	if context == MenuContext {
+		return s.MenuKeyBindings
+	}
+
+	return s.KeyBindings
+}
+
+func (s Settings) Save() error {
+	path, err := UserFilePath(SettingsFile)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// SetOverrides keeps the command line options that win over the saved
+// settings until the game exits. They are never saved.
+func (s *Settings) SetOverrides(options Options) {
+	s.overrides = options
+}
+
+// Effective gives the settings in use, the saved ones with the command line
+// overrides on top.
+func (s Settings) Effective() Settings {
+	if s.overrides.ScreenWidth > 0 && s.overrides.ScreenHeight > 0 {
+		s.ScreenWidth = s.overrides.ScreenWidth
+		s.ScreenHeight = s.overrides.ScreenHeight
+	}
+	s.Fullscreen = s.Fullscreen || s.overrides.Fullscreen
+	s.Debug = s.Debug || s.overrides.Debug
+
+	return s
+}
+
+// Apply makes the window, frame rate, audio and debug settings take effect,
+// with the command line overrides.
+func (s Settings) Apply() {
+	s = s.Effective()
+
+	if rl.IsWindowFullscreen() != s.Fullscreen {
+		rl.ToggleFullscreen()
+	}
+
+	if !s.Fullscreen {
+		rl.SetWindowSize(int32(s.ScreenWidth), int32(s.ScreenHeight))
+	}
+
+	rl.SetTargetFPS(int32(s.TargetFPS))
+	rl.SetMasterVolume(s.Volume)
+	Debug = s.Debug
+}
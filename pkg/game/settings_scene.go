@@ -0,0 +1,455 @@
+package game
+
+import (
+	"fmt"
+
+	"example.com/rplat/pkg/sim"
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+type resolution struct {
+	width  int
+	height int
+}
+
+var resolutions = []resolution{{1280, 700}, {1600, 900}, {1920, 1080}}
+var targetFPSChoices = []int{60, 120, 144, 240}
+var hookModes = []sim.HookMode{sim.HookPull, sim.HookRope}
+
+// Actions listed in the settings scene
+var bindableActions = []Action{ActionMoveLeft, ActionMoveRight, ActionJump, ActionDash, ActionHook, ActionReelIn, ActionReelOut, ActionPortal, ActionHelp, ActionQuit, ActionPause}
+
+// SettingsScene changes the user preferences and saves them when it is closed.
+// It can be pushed from the main menu or the pause menu.
+type SettingsScene struct {
+	inputManager *InputManager
+	selectedItem int
+	items        []string
+	sceneManager *SceneManager
+	settings     *Settings
+	message      string
+	// Action waiting for a key to be pressed, the key replaces its bindings
+	// or is added to them
+	waitingAction   Action
+	waitingAdd      bool
+	pendingModifier int32
+	captured        *keyCapture
+}
+
+// keyCapture is the key read while waiting for one, escape cancels the wait.
+type keyCapture struct {
+	binding   KeyBinding
+	cancelled bool
+}
+
+func NewSettingsScene(sm *SceneManager) *SettingsScene {
+	ss := &SettingsScene{}
+
+	im := NewInputManager(MenuContext, sm.Settings(), sm.Gamepad())
+	ss.inputManager = &im
+	ss.sceneManager = sm
+	ss.settings = sm.Settings()
+
+	ss.items = append(ss.items, "Resolution")
+	ss.items = append(ss.items, "Fullscreen")
+	ss.items = append(ss.items, "Target FPS")
+	ss.items = append(ss.items, "Volume")
+	ss.items = append(ss.items, "Debug overlay")
+	ss.items = append(ss.items, "Reduce motion")
+	ss.items = append(ss.items, "Toggle hook")
+	ss.items = append(ss.items, "Hook mode")
+	for _, action := range bindableActions {
+		ss.items = append(ss.items, action.String())
+	}
+	ss.items = append(ss.items, "Back")
+
+	return ss
+}
+
+func (ss *SettingsScene) Init() {
+	ss.selectedItem = 0
+	ss.message = ""
+	ss.stopWaiting()
+}
+
+// PollInputs waits for the key to bind. raylib only keeps the pressed and
+// released keys for a frame, while the fixed steps reading inputs can run
+// several times or not at all in one, so the key is read here once per frame
+// and bound on the next step.
+func (ss *SettingsScene) PollInputs() {
+	if ss.waitingAction == ActionNone || ss.captured != nil {
+		return
+	}
+
+	held := heldModifiers()
+
+	for key := rl.GetKeyPressed(); key != 0; key = rl.GetKeyPressed() {
+		if key == int32(rl.KEY_ESCAPE) {
+			ss.captured = &keyCapture{cancelled: true}
+			return
+		}
+
+		// A modifier is only bound alone once released, so it can start a combination
+		if isModifierKey(key) {
+			ss.pendingModifier = key
+		} else if _, ok := keyNames[key]; ok {
+			ss.captured = &keyCapture{binding: withModifiers(key, false, held)}
+			return
+		}
+	}
+
+	if ss.pendingModifier != 0 && rl.IsKeyReleased(ss.pendingModifier) {
+		ss.captured = &keyCapture{binding: KeyBinding{Key: ss.pendingModifier}}
+		return
+	}
+
+	for button := range mouseNames {
+		if rl.IsMouseButtonPressed(button) {
+			ss.captured = &keyCapture{binding: withModifiers(button, true, held)}
+			return
+		}
+	}
+}
+
+func (ss *SettingsScene) UpdateInputs() {
+	if ss.captured != nil {
+		captured := *ss.captured
+		ss.captured = nil
+
+		if captured.cancelled {
+			ss.stopWaiting()
+		} else {
+			ss.capture(captured.binding)
+		}
+		return
+	}
+
+	if ss.waitingAction == ActionNone {
+		ss.inputManager.Update()
+	}
+}
+
+func (ss *SettingsScene) ClearInputs() {
+	ss.inputManager.Clear()
+}
+
+func (ss *SettingsScene) ResetInputs() {
+	ss.inputManager.Reset()
+}
+
+func (ss *SettingsScene) End() {
+
+}
+
+func (ss *SettingsScene) HandleEvents() {
+	ss.sceneManager.Dispatch(ss.inputManager.events, ActionHandlers{
+		ActionMoveUp: func() {
+			ss.selectedItem = cycle(ss.selectedItem, -1, len(ss.items))
+		},
+		ActionMoveDown: func() {
+			ss.selectedItem = cycle(ss.selectedItem, 1, len(ss.items))
+		},
+		ActionMoveLeft: func() {
+			item := ss.items[ss.selectedItem]
+			if action, ok := bindableAction(item); ok {
+				ss.removeLastBinding(action)
+			} else {
+				ss.change(item, -1)
+			}
+		},
+		ActionMoveRight: func() {
+			item := ss.items[ss.selectedItem]
+			if action, ok := bindableAction(item); ok {
+				ss.waitForKey(action, true)
+			} else {
+				ss.change(item, 1)
+			}
+		},
+		ActionBack:  ss.close,
+		ActionClick: nil,
+		ActionValidate: func() {
+			item := ss.items[ss.selectedItem]
+			if item == "Back" {
+				ss.close()
+			} else if action, ok := bindableAction(item); ok {
+				ss.waitForKey(action, false)
+			} else {
+				ss.change(item, 1)
+			}
+		},
+	})
+}
+
+// change moves the value of a setting by a step and applies it.
+func (ss *SettingsScene) change(item string, step int) {
+	s := ss.settings
+
+	switch item {
+	case "Resolution":
+		current := -1
+		for i, r := range resolutions {
+			if r.width == s.ScreenWidth && r.height == s.ScreenHeight {
+				current = i
+			}
+		}
+		r := resolutions[cycle(current, step, len(resolutions))]
+		s.ScreenWidth = r.width
+		s.ScreenHeight = r.height
+	case "Fullscreen":
+		s.Fullscreen = !s.Fullscreen
+	case "Target FPS":
+		current := -1
+		for i, fps := range targetFPSChoices {
+			if fps == s.TargetFPS {
+				current = i
+			}
+		}
+		s.TargetFPS = targetFPSChoices[cycle(current, step, len(targetFPSChoices))]
+	case "Volume":
+		s.Volume += float32(step) * 0.1
+		if s.Volume < 0 {
+			s.Volume = 0
+		} else if s.Volume > 1 {
+			s.Volume = 1
+		}
+	case "Debug overlay":
+		s.Debug = !s.Debug
+	case "Reduce motion":
+		s.ReduceMotion = !s.ReduceMotion
+	case "Toggle hook":
+		s.ToggleHook = !s.ToggleHook
+	case "Hook mode":
+		current := -1
+		for i, mode := range hookModes {
+			if mode == s.HookMode {
+				current = i
+			}
+		}
+		s.HookMode = hookModes[cycle(current, step, len(hookModes))]
+	default:
+		return
+	}
+
+	s.Apply()
+}
+
+// waitForKey starts listening for a key from the next frame on, the key that
+// validated the item only shows up in the key queue of this one.
+func (ss *SettingsScene) waitForKey(action Action, add bool) {
+	ss.waitingAction = action
+	ss.waitingAdd = add
+	ss.pendingModifier = 0
+	ss.captured = nil
+	ss.message = ""
+}
+
+func (ss *SettingsScene) stopWaiting() {
+	ss.waitingAction = ActionNone
+	ss.pendingModifier = 0
+	ss.captured = nil
+	// The key ending the wait must not also move through the menu
+	ss.inputManager.Reset()
+}
+
+func (ss *SettingsScene) capture(binding KeyBinding) {
+	action := ss.waitingAction
+	ss.stopWaiting()
+
+	if ss.waitingAdd {
+		ss.addBinding(action, binding)
+	} else {
+		ss.replaceBindings(action, binding)
+	}
+}
+
+// replaceBindings makes the binding the only one of the action. An action
+// already using it gets the previous binding of the action instead.
+func (ss *SettingsScene) replaceBindings(action Action, binding KeyBinding) {
+	bindings := ss.settings.KeyBindings
+	previous := bindings[action]
+
+	for _, other := range bindableActions {
+		if other == action {
+			continue
+		}
+
+		for i, b := range bindings[other] {
+			if b != binding {
+				continue
+			}
+
+			if len(previous) > 0 && !hasBinding(bindings[other], previous[0]) {
+				bindings[other][i] = previous[0]
+			} else {
+				bindings[other] = append(bindings[other][:i], bindings[other][i+1:]...)
+			}
+			break
+		}
+	}
+
+	bindings[action] = []KeyBinding{binding}
+}
+
+// addBinding gives the action one more binding, unless another action uses it.
+func (ss *SettingsScene) addBinding(action Action, binding KeyBinding) {
+	bindings := ss.settings.KeyBindings
+
+	for _, other := range bindableActions {
+		if hasBinding(bindings[other], binding) {
+			ss.message = fmt.Sprintf("%v is already used by %v", binding, other)
+			return
+		}
+	}
+
+	bindings[action] = append(bindings[action], binding)
+}
+
+// removeLastBinding drops the last binding of the action, the first one is kept.
+func (ss *SettingsScene) removeLastBinding(action Action) {
+	bindings := ss.settings.KeyBindings
+
+	if len(bindings[action]) > 1 {
+		bindings[action] = bindings[action][:len(bindings[action])-1]
+	}
+}
+
+func (ss *SettingsScene) close() {
+	err := ss.settings.Save()
+	if err != nil {
+		fmt.Println("error: could not save settings:", err)
+	}
+
+	ss.sceneManager.PopScene()
+}
+
+func (ss *SettingsScene) Update(deltaTime float32) {
+
+}
+
+func (ss SettingsScene) ShouldExit() bool {
+	return false
+}
+
+func (ss SettingsScene) UpdateBelow() bool {
+	return false
+}
+
+func (ss SettingsScene) DrawBelow() bool {
+	return false
+}
+
+func (ss SettingsScene) Draw(factor float64) {
+	rl.ClearBackground(rl.RayWhite)
+
+	rl.DrawText("Settings", 500, 40, 50, rl.Black)
+	rl.DrawText("Use LEFT and RIGHT to change a value", 300, 100, 20, rl.Gray)
+	rl.DrawText("On a key binding, ENTER sets a key, RIGHT adds one and LEFT removes the last one", 300, 120, 20, rl.Gray)
+
+	// The list scrolls to keep the selected item in view
+	first, last := ss.shownItems()
+	if first > 0 {
+		rl.DrawText("...", 400, 140, 20, rl.Gray)
+	}
+
+	for i := first; i < last; i++ {
+		item := ss.items[i]
+		y := int32(170 + (i-first)*30)
+		rl.DrawText(item, 400, y, 20, ss.ColorFromItem(i))
+		rl.DrawText(ss.value(item), 650, y, 20, ss.ColorFromItem(i))
+	}
+
+	if last < len(ss.items) {
+		rl.DrawText("...", 400, int32(170+settingsShownItems*30), 20, rl.Gray)
+	}
+
+	rl.DrawText(ss.message, 400, int32(200+settingsShownItems*30), 20, rl.Red)
+}
+
+// Number of items drawn at once, enough to fit the smallest resolution
+const settingsShownItems = 14
+
+// shownItems returns the range of items drawn, centered on the selected one.
+func (ss SettingsScene) shownItems() (int, int) {
+	if len(ss.items) <= settingsShownItems {
+		return 0, len(ss.items)
+	}
+
+	first := ss.selectedItem - settingsShownItems/2
+	if first < 0 {
+		first = 0
+	}
+	if first > len(ss.items)-settingsShownItems {
+		first = len(ss.items) - settingsShownItems
+	}
+
+	return first, first + settingsShownItems
+}
+
+func (ss SettingsScene) value(item string) string {
+	s := ss.settings
+
+	switch item {
+	case "Resolution":
+		return fmt.Sprintf("%vx%v", s.ScreenWidth, s.ScreenHeight)
+	case "Fullscreen":
+		return onOff(s.Fullscreen)
+	case "Target FPS":
+		return fmt.Sprintf("%v", s.TargetFPS)
+	case "Volume":
+		return fmt.Sprintf("%.0f%%", s.Volume*100)
+	case "Debug overlay":
+		return onOff(s.Debug)
+	case "Reduce motion":
+		return onOff(s.ReduceMotion)
+	case "Toggle hook":
+		return onOff(s.ToggleHook)
+	case "Hook mode":
+		return string(s.HookMode)
+	}
+
+	if action, ok := bindableAction(item); ok {
+		if action == ss.waitingAction {
+			return "Press a key, ESCAPE to cancel"
+		}
+		return BindingsText(s.KeyBindings[action])
+	}
+
+	return ""
+}
+
+func (ss SettingsScene) ColorFromItem(item_index int) rl.Color {
+	if item_index == ss.selectedItem {
+		return rl.Green
+	} else {
+		return rl.Black
+	}
+}
+
+func hasBinding(bindings []KeyBinding, binding KeyBinding) bool {
+	for _, b := range bindings {
+		if b == binding {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bindableAction gives the action listed by a menu item, if any.
+func bindableAction(item string) (Action, bool) {
+	for _, action := range bindableActions {
+		if action.String() == item {
+			return action, true
+		}
+	}
+
+	return ActionNone, false
+}
+
+func onOff(value bool) string {
+	if value {
+		return "On"
+	}
+
+	return "Off"
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	rl "github.com/chunqian/go-raylib/raylib"
+)
+
+type TransitionPhase int
+
+const (
+	// The old scenes are being hidden, they are drawn but frozen
+	TransitionOut TransitionPhase = iota
+	// The new scene is being revealed, it runs normally
+	TransitionIn
+)
+
+// Transition is drawn over the scenes while the SceneManager swaps them.
+type Transition interface {
+	// Durations of the phase hiding the old scenes and of the phase revealing
+	// the new one, in seconds. The scenes are swapped in between.
+	Durations() (out, in float64)
+	// Draw is called after the scenes were drawn, progress goes from 0 to 1 during each phase.
+	Draw(phase TransitionPhase, progress float64)
+}
+
+// SnapshotTransition is implemented by transitions that need a picture of the
+// old scenes after they ended.
+type SnapshotTransition interface {
+	// Capture is called right before the old scenes end, draw renders them one last time.
+	Capture(draw func())
+	Release()
+}
+
+// Cut swaps the scenes instantly.
+type Cut struct{}
+
+func (c Cut) Durations() (float64, float64) {
+	return 0, 0
+}
+
+func (c Cut) Draw(phase TransitionPhase, progress float64) {
+
+}
+
+// Fade fades the old scenes out to a color, then fades the new one in.
+type Fade struct {
+	Duration float64
+	Color    rl.Color
+}
+
+func NewFade() Fade {
+	return Fade{Duration: 0.4, Color: rl.Black}
+}
+
+func (f Fade) Durations() (float64, float64) {
+	return f.Duration / 2, f.Duration / 2
+}
+
+func (f Fade) Draw(phase TransitionPhase, progress float64) {
+	alpha := progress
+	if phase == TransitionIn {
+		alpha = 1 - progress
+	}
+
+	rl.DrawRectangle(0, 0, rl.GetScreenWidth(), rl.GetScreenHeight(), rl.Fade(f.Color, float32(alpha)))
+}
+
+// Wipe covers the old scenes with a color from left to right, then uncovers
+// the new one the same way.
+type Wipe struct {
+	Duration float64
+	Color    rl.Color
+}
+
+func NewWipe() Wipe {
+	return Wipe{Duration: 0.5, Color: rl.Black}
+}
+
+func (w Wipe) Durations() (float64, float64) {
+	return w.Duration / 2, w.Duration / 2
+}
+
+func (w Wipe) Draw(phase TransitionPhase, progress float64) {
+	width := float64(rl.GetScreenWidth())
+	height := rl.GetScreenHeight()
+
+	if phase == TransitionOut {
+		rl.DrawRectangle(0, 0, int32(width*progress), height, w.Color)
+	} else {
+		rl.DrawRectangle(int32(width*progress), 0, int32(width*(1-progress)), height, w.Color)
+	}
+}
+
+// Crossfade swaps the scenes right away and fades the last picture of the old
+// scenes out over the new one.
+type Crossfade struct {
+	Duration float64
+	snapshot *rl.RenderTexture2D
+}
+
+func NewCrossfade() *Crossfade {
+	return &Crossfade{Duration: 0.4}
+}
+
+func (c *Crossfade) Durations() (float64, float64) {
+	return 0, c.Duration
+}
+
+func (c *Crossfade) Capture(draw func()) {
+	c.Release()
+
+	snapshot := rl.LoadRenderTexture(rl.GetScreenWidth(), rl.GetScreenHeight())
+	rl.BeginTextureMode(snapshot)
+	draw()
+	rl.EndTextureMode()
+
+	c.snapshot = &snapshot
+}
+
+func (c *Crossfade) Release() {
+	if c.snapshot != nil {
+		rl.UnloadRenderTexture(*c.snapshot)
+		c.snapshot = nil
+	}
+}
+
+func (c *Crossfade) Draw(phase TransitionPhase, progress float64) {
+	if c.snapshot == nil {
+		return
+	}
+
+	texture := c.snapshot.Texture
+	// Render textures are upside down
+	source := rl.Rectangle{X: 0, Y: 0, Width: float32(texture.Width), Height: -float32(texture.Height)}
+	rl.DrawTextureRec(texture, source, rl.Vector2{X: 0, Y: 0}, rl.Fade(rl.White, float32(1-progress)))
+}
+
+func drawLoadingScreen(elapsed float64) {
+	dots := int(elapsed*3) % 4
+	text := "Loading" + "..."[:dots]
+
+	rl.ClearBackground(rl.RayWhite)
+	rl.DrawText(text, rl.GetScreenWidth()/2-80, rl.GetScreenHeight()/2-20, 40, rl.Black)
+}
@@ -5,89 +5,89 @@ import (
 	"math/rand"
 	"time"
 
+	"example.com/rplat/pkg/sim"
 	rl "github.com/chunqian/go-raylib/raylib"
 )
 
-// Workaround to be able to call methods on a pointer on my interface
-type TuorialGameSceneWrapper struct {
-	tgs *TuorialGameScene
-}
-
-func NewTuorialGameSceneWrapper(sm *SceneManager) TuorialGameSceneWrapper {
-	return TuorialGameSceneWrapper{tgs: NewTuorialGameScene(sm)}
-}
-
-// Implement Scene interface
-func (tgsw TuorialGameSceneWrapper) Init() {
-	tgsw.tgs.Init()
-}
-
-func (tgsw TuorialGameSceneWrapper) UpdateInputs() {
-	tgsw.tgs.UpdateInputs()
-}
+type TuorialGameScene struct {
+	world            *sim.World
+	level            Map
+	mapPath          string
+	defaultMapPath   string
+	mapConfiguration sim.MapConfiguration
+	tilesetPath      string
+	inputManager     *InputManager
+	score            int
+	sceneManager     *SceneManager
+	gameEnded        bool
+	aim              *sim.FixedInput
+	seed             int64
+	rng              *rand.Rand
+}
+
+func NewTuorialGameScene(sm *SceneManager, options Options) *TuorialGameScene {
+	tgs := &TuorialGameScene{}
 
-func (tgsw TuorialGameSceneWrapper) ClearInputs() {
-	tgsw.tgs.ClearInputs()
-}
+	im := NewInputManager(GameplayContext, sm.Settings(), sm.Gamepad())
 
-func (tgsw TuorialGameSceneWrapper) HandleEvents() {
-	tgsw.tgs.HandleEvents()
-}
+	tgs.mapPath = options.MapPath
+	tgs.defaultMapPath = options.MapPath
+	tgs.seed = options.Seed
+	tgs.aim = &sim.FixedInput{}
+	tgs.inputManager = &im
+	tgs.inputManager.FollowHook(func() bool {
+		return tgs.world != nil && tgs.world.Player.HookLaunched()
+	})
+	tgs.sceneManager = sm
 
-func (tgsw TuorialGameSceneWrapper) Update(dt float32) {
-	tgsw.tgs.Update(dt)
+	return tgs
 }
 
-func (tgsw TuorialGameSceneWrapper) Draw(factor float64) {
-	tgsw.tgs.Draw(factor)
-}
+// Load reads the level and checks its tileset can be loaded.
+func (tgs *TuorialGameScene) Load() error {
+	assets := tgs.sceneManager.Assets()
 
-func (tgsw TuorialGameSceneWrapper) End() {
-	tgsw.tgs.End()
-}
+	mc, err := assets.Map(tgs.mapPath)
+	if err != nil {
+		return err
+	}
 
-func (tgsw TuorialGameSceneWrapper) ShouldExit() bool {
-	return tgsw.tgs.ShouldExit()
-}
+	err = CheckAsset(TilesetPath(tgs.mapPath, mc))
+	if err != nil {
+		assets.ReleaseMap(tgs.mapPath)
+		return err
+	}
 
-type TuorialGameScene struct {
-	player       *Player
-	level        Map
-	inputManager *InputManager
-	stars        []Star
-	score        int
-	sceneManager *SceneManager
-	gameEnded    bool
-	helpOpen     bool
+	tgs.mapConfiguration = mc
+	return nil
 }
 
-func NewTuorialGameScene(sm *SceneManager) *TuorialGameScene {
-	tgs := &TuorialGameScene{}
-
-	// Load level
-	mc := NewMapConfiguration("./assets/map.json")
-	tileset := NewTileset("./assets/tileset.png", mc.TileWidth, mc.TileHeight)
-	im := NewInputManager()
-
-	tgs.level = NewMap(mc, tileset)
-	tgs.inputManager = &im
-	tgs.sceneManager = sm
+func (tgs *TuorialGameScene) Init() {
+	assets := tgs.sceneManager.Assets()
+	tilesetPath := TilesetPath(tgs.mapPath, tgs.mapConfiguration)
+
+	// Without its tileset the level is drawn without tiles, End must not give
+	// back a tileset that was never taken
+	tileset, err := assets.Tileset(tilesetPath, tgs.mapConfiguration.TileWidth, tgs.mapConfiguration.TileHeight)
+	if err != nil {
+		fmt.Println("error:", err)
+		tgs.tilesetPath = ""
+	} else {
+		tgs.tilesetPath = tilesetPath
+	}
 
-	return tgs
-}
+	tgs.level = NewMap(tgs.mapConfiguration, tileset)
+	tgs.world = sim.NewWorld(tgs.mapConfiguration, tgs.aim)
 
-func (tgs *TuorialGameScene) Init() {
-	player := Player{
-		pos:          rl.Vector2{X: 32, Y: 32},
-		lastPos:      rl.Vector2{X: 20, Y: 20},
-		velocity:     rl.Vector2{X: 0, Y: 0},
-		lastVelocity: rl.Vector2{X: 0, Y: 0},
-		size:         rl.Vector2{X: 32, Y: 64},
-		canJump:      true,
-		color:        rl.Red,
+	seed := tgs.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
 	}
 
-	tgs.player = &player
+	tgs.rng = rand.New(rand.NewSource(seed))
+	tgs.world.HookMode = tgs.sceneManager.Settings().HookMode
+	tgs.world.Reset()
+	tgs.score = 0
 	tgs.gameEnded = false
 
 	for i := 0; i < 2; i++ {
@@ -97,70 +97,76 @@ func (tgs *TuorialGameScene) Init() {
 }
 
 func (tgs *TuorialGameScene) End() {
-	tgs.stars = nil
-}
+	assets := tgs.sceneManager.Assets()
+	assets.ReleaseMap(tgs.mapPath)
+	if tgs.tilesetPath != "" {
+		assets.ReleaseTileset(tgs.tilesetPath, tgs.mapConfiguration.TileWidth, tgs.mapConfiguration.TileHeight)
+		tgs.tilesetPath = ""
+	}
 
-func (tgs *TuorialGameScene) SpawnStar() bool {
-	s := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(s)
-	x := r.Intn(ScreenWidth)
-	y := r.Intn(ScreenHeight)
+	tgs.world.Reset()
+}
 
-	star := Star{rl.Vector2{X: float32(x), Y: float32(y)}}
+// SetMapPath changes the map played from the next time the scene is entered.
+func (tgs *TuorialGameScene) SetMapPath(path string) {
+	tgs.mapPath = path
+}
 
-	for _, wall := range tgs.level.walls {
-		if isColliding(star.Rectangle(), wall) {
-			return false
-		}
-	}
+func (tgs *TuorialGameScene) ResetMapPath() {
+	tgs.mapPath = tgs.defaultMapPath
+}
 
-	for _, exStar := range tgs.stars {
-		if isColliding(star.Rectangle(), exStar.Rectangle()) {
-			return false
-		}
-	}
+func (tgs *TuorialGameScene) SpawnStar() bool {
+	x := tgs.rng.Intn(int(tgs.world.Width))
+	y := tgs.rng.Intn(int(tgs.world.Height))
 
-	tgs.stars = append(tgs.stars, star)
-	return true
+	return tgs.world.SpawnStar(sim.Vector2{X: float32(x), Y: float32(y)})
 }
 
 func (tgs *TuorialGameScene) UpdateInputs() {
 	tgs.inputManager.Update()
+	tgs.aim.Aim = tgs.inputManager.AimPosition(tgs.world.Player.Position())
 }
 
 func (tgs *TuorialGameScene) ClearInputs() {
 	tgs.inputManager.Clear()
 }
 
+func (tgs *TuorialGameScene) ResetInputs() {
+	tgs.inputManager.Reset()
+}
+
 func (tgs *TuorialGameScene) HandleEvents() {
-	for i := 0; i < len(tgs.inputManager.events); i++ {
-		if tgs.gameEnded && tgs.inputManager.events[i] == "validate" {
-			tgs.sceneManager.SwapScene("main_menu")
-		} else {
-			switch e := tgs.inputManager.events[i]; e {
-			case "help":
-				tgs.helpOpen = !tgs.helpOpen
-			case "move_right":
-				tgs.player.MoveRight()
-			case "move_left":
-				tgs.player.MoveLeft()
-			case "jump":
-				tgs.player.Jump()
-			case "hook":
-				tgs.player.Hook()
-			case "stop_hook":
-				tgs.player.StopHook()
-			case "dash":
-				tgs.player.Dash()
-			case "portal":
-				tgs.player.FirePortal(tgs.level.walls)
-			case "quit":
-				tgs.gameEnded = true
-			default:
-				// Unknown event
-			}
-		}
-	}
+	player := tgs.world.Player
+
+	tgs.sceneManager.Dispatch(tgs.inputManager.events, ActionHandlers{
+		ActionHelp: func() {
+			tgs.sceneManager.PushScene("help")
+		},
+		ActionPause: func() {
+			tgs.sceneManager.PushScene("pause")
+		},
+		ActionMoveRight: func() {
+			player.MoveRight(tgs.inputManager.MoveStrength())
+		},
+		ActionMoveLeft: func() {
+			player.MoveLeft(tgs.inputManager.MoveStrength())
+		},
+		ActionJump:     player.Jump,
+		ActionStopJump: player.ReleaseJump,
+		ActionHook:     player.Hook,
+		ActionStopHook: player.StopHook,
+		ActionReelIn:   player.ReelIn,
+		ActionReelOut:  player.ReelOut,
+		ActionDash:     player.Dash,
+		ActionPortal: func() {
+			player.FirePortal(tgs.world.Walls)
+		},
+		ActionQuit: func() {
+			tgs.gameEnded = true
+		},
+		ActionValidate: nil,
+	})
 }
 
 func (tgs TuorialGameScene) ShouldExit() bool {
@@ -169,73 +175,32 @@ func (tgs TuorialGameScene) ShouldExit() bool {
 
 func (tgs *TuorialGameScene) Update(deltaTime float32) {
 	if tgs.gameEnded {
-		tgs.sceneManager.SwapScene("main_menu")
-	}
-
-	if tgs.helpOpen {
+		tgs.sceneManager.SwapSceneWith("main_menu", NewFade())
 		return
 	}
 
-	tgs.player.color = rl.Green
-	tgs.player.lastPos = tgs.player.pos
-	tgs.player.lastVelocity = tgs.player.velocity
-
-	tgs.player.Update(deltaTime)
-	tgs.player.checkAndHandleCollisions(tgs.level.walls)
-
-	starsToRemove := []int{}
-	for i, star := range tgs.stars {
-		if isColliding(tgs.player.Rectangle(), star.Rectangle()) {
-			tgs.score += 10
-			starsToRemove = append(starsToRemove, i)
-			for !tgs.SpawnStar() {
-			}
+	collected := tgs.world.Step(deltaTime)
+	for range collected {
+		tgs.score += 10
+		for !tgs.SpawnStar() {
 		}
 	}
-
-	for _, j := range starsToRemove {
-		tgs.stars = RemoveIndexStar(tgs.stars, j)
-	}
 }
 
 func (tgs TuorialGameScene) Draw(factor float64) {
-	rl.BeginDrawing()
-	defer rl.EndDrawing()
-
 	rl.ClearBackground(rl.RayWhite)
 
-	if tgs.helpOpen {
-		tgs.DrawHelp()
-	} else {
-		tgs.DrawGame(factor)
-	}
-}
-
-func (tgs TuorialGameScene) DrawHelp() {
-	leftOffset := 150
-
-	rl.DrawText("Help menu press H again to close.", 250, 50, 50, rl.Black)
-	rl.DrawText("Press BACKSPACE to leave", 300, 110, 50, rl.Black)
-
-	rl.DrawText("In random game mode you have 30 seconds to catch all the stars", int32(leftOffset), 200, 30, rl.Black)
-	rl.DrawText("Your score depends on how much remaining time you still get.", int32(leftOffset), 230, 30, rl.Black)
-	rl.DrawText("To achieve your mission you have access to multiple fast travel skills", int32(leftOffset), 270, 30, rl.Black)
-
-	leftOffset = leftOffset - 130
-	rl.DrawText("Teeworlds fan ? You can use a grappling hook using your mouse right click !", int32(leftOffset), 350, 30, rl.Black)
-	rl.DrawText("Already played portal ? You can fire your portal gun using your mouse left click !", int32(leftOffset), 380, 30, rl.Black)
-	rl.DrawText("And finally, you can dash in the direction you are going using left shift.", int32(leftOffset), 410, 30, rl.Black)
+	tgs.DrawGame(factor)
 }
 
 func (tgs TuorialGameScene) DrawGame(factor float64) {
 	tgs.level.Draw()
-	tgs.player.Draw(factor)
-	for _, star := range tgs.stars {
-		star.Draw()
-	}
+	tgs.world.Draw(RaylibRenderer{}, factor)
 
 	scoreText := fmt.Sprintf("Score: %v", tgs.score)
 	rl.DrawText(scoreText, 500, 60, 40, rl.Black)
 
 	rl.DrawText("Press enter H to open help", 350, 200, 30, rl.Black)
+
+	drawHud(tgs.world.Player)
 }
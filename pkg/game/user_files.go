@@ -0,0 +1,23 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// UserFilePath returns the path of a file kept in the rplat folder of the user
+// config directory, creating the folder if needed.
+func UserFilePath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir = filepath.Join(dir, "rplat")
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, name), nil
+}
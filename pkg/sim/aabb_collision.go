@@ -1,12 +1,10 @@
-package game
+package sim
 
 import (
 	"math"
-
-	rl "github.com/chunqian/go-raylib/raylib"
 )
 
-func isColliding(main_body, other_body rl.Rectangle) bool {
+func IsColliding(main_body, other_body Rectangle) bool {
 	var p1x = math.Max(float64(main_body.X), float64(other_body.X))
 	var p1y = math.Max(float64(main_body.Y), float64(other_body.Y))
 	var p2x = math.Min(float64(main_body.X+main_body.Width), float64(other_body.X+other_body.Width))
@@ -19,7 +17,7 @@ func isColliding(main_body, other_body rl.Rectangle) bool {
 	}
 }
 
-func collisionDirection(main_body, other_body rl.Rectangle) string {
+func CollisionDirection(main_body, other_body Rectangle) string {
 	left := (main_body.X + main_body.Width) - other_body.X
 	right := (other_body.X + other_body.Width) - main_body.X
 	bottom := (main_body.Y + main_body.Height) - other_body.Y
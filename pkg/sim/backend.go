@@ -0,0 +1,33 @@
+package sim
+
+// The simulation never talks to a window, a keyboard or a GPU directly.
+// Everything it needs from the outside world goes through the interfaces
+// below, so the same code runs inside the raylib game and in a headless
+// process (see null_backend.go).
+
+// Input gives the player intents that are not plain actions, like where
+// the hook and the portal gun are aimed.
+type Input interface {
+	AimPosition() Vector2
+}
+
+// Renderer draws simulation entities.
+type Renderer interface {
+	DrawRectangle(rec Rectangle, color Color)
+	DrawLine(start, end Vector2, thick float32, color Color)
+}
+
+type Color struct {
+	R, G, B, A uint8
+}
+
+var (
+	Yellow = Color{R: 253, G: 249, B: 0, A: 255}
+	Orange = Color{R: 255, G: 161, B: 0, A: 255}
+	Red    = Color{R: 230, G: 41, B: 55, A: 255}
+	Green  = Color{R: 0, G: 228, B: 48, A: 255}
+	Blue   = Color{R: 0, G: 121, B: 241, A: 255}
+	Brown  = Color{R: 127, G: 106, B: 79, A: 255}
+	Gray   = Color{R: 130, G: 130, B: 130, A: 255}
+	Black  = Color{R: 0, G: 0, B: 0, A: 255}
+)
@@ -0,0 +1,92 @@
+package sim
+
+const HookSpeed = 1800
+const HookVerticalForce = 30
+const HookHorizontalForce = 60
+
+// The hook flies back to the player once this far without hooking anything
+const HookMaxDistance = 600
+
+// Shortest rope, and the speed it is reeled in or out in rope mode
+const HookMinLength = 40
+const HookReelSpeed = 300
+
+// On a rope the air slows the swing down much less than the usual friction,
+// and moving only gives a small push
+const HookRopeFriction = 0.995
+const HookRopeControl = 0.05
+
+// HookMode selects how the hook moves a hooked player.
+type HookMode string
+
+const (
+	// The player is pulled toward the hook, like in Teeworlds
+	HookPull HookMode = "pull"
+	// The player swings at the end of a rope of fixed length
+	HookRope HookMode = "rope"
+)
+
+type Hook struct {
+	pos, lastPos, velocity, size Vector2
+	hooked, retracting           bool
+	// Rope length in rope mode
+	length float32
+	// Entity pulled or ridden, and where the hook holds it when ridden
+	target       EntityID
+	targetOffset Vector2
+	color        Color
+}
+
+func NewHook(player Player) Hook {
+	dir := DirectionVectorFromVectors(player.pos, player.input.AimPosition())
+
+	return Hook{
+		pos:      player.pos,
+		lastPos:  player.pos,
+		velocity: Vector2{X: dir.X * HookSpeed, Y: dir.Y * HookSpeed},
+		size:     Vector2{X: 32, Y: 32},
+		hooked:   false,
+		color:    Orange,
+	}
+}
+
+func (h Hook) Rectangle() Rectangle {
+	// TODO: Avoid creating new rectangle each time if this becomes a performance bottleneck
+	return Rectangle{
+		X:      h.pos.X,
+		Y:      h.pos.Y,
+		Width:  h.size.X,
+		Height: h.size.Y,
+	}
+}
+
+// SaveState keeps the current position as the previous state used to interpolate drawing.
+func (h *Hook) SaveState() {
+	h.lastPos = h.pos
+}
+
+// InterpolatedPosition returns the position between the last two simulation steps.
+func (h Hook) InterpolatedPosition(factor float64) Vector2 {
+	return LerpVec2(h.lastPos, h.pos, factor)
+}
+
+func (h *Hook) SolveCollision(wall Rectangle, direction string) {
+	switch direction {
+	case "bottom":
+		h.pos.Y = wall.Y - h.size.Y
+		h.velocity.Y = 0
+	case "right":
+		h.pos.X = wall.X + wall.Width
+		h.velocity.X = 0
+	case "left":
+		h.pos.X = wall.X - h.size.X
+		h.velocity.X = 0
+	case "top":
+		h.pos.Y = wall.Y + wall.Height
+		h.velocity.Y = 0
+	}
+
+	h.velocity.X = 0
+	h.velocity.Y = 0
+	h.hooked = true
+}
@@ -0,0 +1,29 @@
+package sim
+
+// EntityID identifies an entity of the world for as long as it exists, 0 is
+// no entity.
+type EntityID int
+
+// HookReaction is what happens when the hook catches an entity.
+type HookReaction int
+
+const (
+	// The hook goes through the entity
+	HookIgnore HookReaction = iota
+	// The hook flies back to the player bringing the entity along
+	HookPullTarget
+	// The entity is collected as soon as the hook catches it
+	HookCollectTarget
+	// The hook holds on the entity like on a wall and follows it when it moves
+	HookRideTarget
+)
+
+// HookTarget is implemented by the entities the hook can catch. Each entity
+// type declares its reaction.
+type HookTarget interface {
+	ID() EntityID
+	Rectangle() Rectangle
+	HookReaction() HookReaction
+	// MoveTo is used to bring pulled entities along with the hook
+	MoveTo(pos Vector2)
+}
@@ -0,0 +1,327 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+// Top left corner of the test map, with nothing for the hook to catch on the
+// right for more than HookMaxDistance
+var topLeft = Vector2{X: 64, Y: 32}
+
+func TestHookRange(t *testing.T) {
+	tests := []struct {
+		name       string
+		pos        Vector2
+		aim        Vector2
+		wantHooked bool
+	}{
+		{name: "wall in range", pos: openFloor, aim: Vector2{X: 0, Y: -1000}, wantHooked: true},
+		{name: "nothing in range", pos: topLeft, aim: Vector2{X: 1000, Y: 0}, wantHooked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			dropAt(tw, tt.pos)
+			aimFrom(tw, tt.aim)
+
+			p := tw.Player
+			p.Hook()
+
+			var farthest float32
+			tw.stepUntil(t, nil, func() bool {
+				if !p.HookLaunched() {
+					return true
+				}
+				if d := Vector2Distance(p.pos, p.hook.pos); d > farthest {
+					farthest = d
+				}
+				return p.State() == StateHooked
+			})
+
+			if hooked := p.State() == StateHooked; hooked != tt.wantHooked {
+				t.Errorf("hooked = %v, want %v", hooked, tt.wantHooked)
+			}
+			// The hook turns back on the first step past the range
+			if farthest > HookMaxDistance+HookSpeed*testStep {
+				t.Errorf("hook went %v away, want at most %v", farthest, HookMaxDistance)
+			}
+		})
+	}
+}
+
+func TestRopeLength(t *testing.T) {
+	tests := []struct {
+		name  string
+		reel  func(p *Player)
+		steps int
+		// Rope length wanted from the length it had when the hook caught the ceiling
+		want func(length float32) float32
+	}{
+		{
+			name:  "holding",
+			reel:  func(p *Player) {},
+			steps: 50,
+			want:  func(length float32) float32 { return length },
+		},
+		{
+			name:  "reeled in",
+			reel:  (*Player).ReelIn,
+			steps: 50,
+			want:  func(length float32) float32 { return length - HookReelSpeed*50*testStep },
+		},
+		{
+			name:  "reeled in to the shortest",
+			reel:  (*Player).ReelIn,
+			steps: 300,
+			want:  func(float32) float32 { return HookMinLength },
+		},
+		{
+			name:  "reeled out to the longest",
+			reel:  (*Player).ReelOut,
+			steps: 50,
+			want:  func(float32) float32 { return HookMaxDistance },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			p := tw.Player
+			p.SetHookMode(HookRope)
+			hookCeiling(t, tw)
+
+			want := tt.want(p.hook.length)
+			for i := 0; i < tt.steps; i++ {
+				tt.reel(p)
+				tw.run(1)
+			}
+
+			if math.Abs(float64(p.hook.length-want)) > 0.01 {
+				t.Errorf("rope is %v long, want %v", p.hook.length, want)
+			}
+			if d := Vector2Distance(p.pos, p.hook.pos); d > p.hook.length+0.01 {
+				t.Errorf("player is %v away from the hook, past the %v long rope", d, p.hook.length)
+			}
+		})
+	}
+}
+
+// Star on the right of the open floor, in reach of a hook thrown straight right
+var starInReach = Vector2{X: openFloor.X + 200, Y: openFloor.Y + 16}
+
+// hookStar throws the hook at starInReach and waits for it to catch the star.
+func hookStar(t *testing.T, tw testWorld) {
+	tw.SpawnStar(starInReach)
+	aimFrom(tw, Vector2{X: 1000, Y: 0})
+	tw.Player.Hook()
+	tw.stepUntil(t, nil, func() bool { return tw.Player.hook.target != 0 })
+}
+
+func TestHookedStar(t *testing.T) {
+	tests := []struct {
+		name string
+		// Steps the star is brought back for before letting go, 0 to hold on
+		holdSteps     int
+		wantCollected bool
+	}{
+		{name: "held until back", wantCollected: true},
+		{name: "let go on the way", holdSteps: 3, wantCollected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			hookStar(t, tw)
+
+			p := tw.Player
+			if tt.holdSteps > 0 {
+				tw.run(tt.holdSteps)
+				p.StopHook()
+			}
+			released := tw.Stars[0].pos
+
+			tw.stepUntil(t, nil, func() bool { return len(tw.Stars) == 0 || !p.HookLaunched() })
+			tw.run(50)
+
+			if collected := len(tw.Stars) == 0; collected != tt.wantCollected {
+				t.Fatalf("collected = %v, want %v", collected, tt.wantCollected)
+			}
+			// Only touching a star collects it, one let go of stays put
+			if !tt.wantCollected && tw.Stars[0].pos != released {
+				t.Errorf("star moved from %v to %v once let go", released, tw.Stars[0].pos)
+			}
+			if p.Position() != openFloor {
+				t.Errorf("player moved to %v, want the star brought to them", p.Position())
+			}
+		})
+	}
+}
+
+func TestReleasedStarLeavesWalls(t *testing.T) {
+	tests := []struct {
+		name    string
+		release Vector2
+		want    Vector2
+	}{
+		{name: "in the air", release: Vector2{X: 992, Y: 400}, want: Vector2{X: 992, Y: 400}},
+		{name: "in the floor", release: Vector2{X: 992, Y: 660}, want: Vector2{X: 992, Y: 640}},
+		{name: "in a wall", release: Vector2{X: 1240, Y: 352}, want: Vector2{X: 1216, Y: 352}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			hookStar(t, tw)
+
+			// Pulled through a wall and let go there
+			tw.Stars[0].MoveTo(tt.release)
+			tw.Player.StopHook()
+			tw.run(1)
+
+			if len(tw.Stars) != 1 || tw.Stars[0].pos != tt.want {
+				t.Errorf("stars %v, want one at %v", tw.Stars, tt.want)
+			}
+		})
+	}
+}
+
+// movingTarget is a hook target moving at a constant speed.
+type movingTarget struct {
+	id       EntityID
+	rect     Rectangle
+	velocity Vector2
+	reaction HookReaction
+}
+
+func (mt movingTarget) ID() EntityID {
+	return mt.id
+}
+
+func (mt movingTarget) Rectangle() Rectangle {
+	return mt.rect
+}
+
+func (mt movingTarget) HookReaction() HookReaction {
+	return mt.reaction
+}
+
+func (mt *movingTarget) MoveTo(pos Vector2) {
+	mt.rect.X = pos.X
+	mt.rect.Y = pos.Y
+}
+
+// stepWithTargets steps the player the way the world does, with targets that
+// move before the player instead of the stars. It returns the entity caught
+// to be collected, if any.
+func (tw testWorld) stepWithTargets(targets []*movingTarget) EntityID {
+	hookTargets := make([]HookTarget, 0, len(targets))
+	for _, target := range targets {
+		target.rect.X += target.velocity.X * testStep
+		target.rect.Y += target.velocity.Y * testStep
+		hookTargets = append(hookTargets, target)
+	}
+
+	tw.Player.SaveState()
+	tw.Player.Update(testStep)
+	tw.Player.checkAndHandleCollisions(tw.Walls)
+	caught := tw.Player.catchWithHook(hookTargets)
+	tw.Player.followHookTarget(hookTargets)
+
+	return caught
+}
+
+// stepTargetsUntil runs stepWithTargets until done reports true and returns
+// the entity caught to be collected, if any.
+func (tw testWorld) stepTargetsUntil(t *testing.T, targets []*movingTarget, done func() bool) EntityID {
+	t.Helper()
+
+	for step := 1; step <= maxTestSteps; step++ {
+		if caught := tw.stepWithTargets(targets); caught != 0 || done() {
+			return caught
+		}
+	}
+
+	t.Fatalf("still waiting after %v steps", maxTestSteps)
+	return 0
+}
+
+func TestHookTargetReactions(t *testing.T) {
+	tests := []struct {
+		name          string
+		reaction      HookReaction
+		wantCaught    bool
+		wantHooked    bool
+		wantRetracted bool
+	}{
+		{name: "ignored", reaction: HookIgnore, wantHooked: true},
+		{name: "pulled", reaction: HookPullTarget, wantRetracted: true},
+		{name: "collected", reaction: HookCollectTarget, wantCaught: true, wantRetracted: true},
+		{name: "ridden", reaction: HookRideTarget, wantHooked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			target := &movingTarget{
+				id:       7,
+				rect:     Rectangle{X: openFloor.X, Y: 300, Width: 32, Height: 32},
+				reaction: tt.reaction,
+			}
+
+			p := tw.Player
+			aimFrom(tw, Vector2{X: 0, Y: -1000})
+			p.Hook()
+
+			caught := tw.stepTargetsUntil(t, []*movingTarget{target}, func() bool { return p.hook.hooked || p.hook.retracting })
+
+			if (caught == target.id) != tt.wantCaught {
+				t.Errorf("caught %v, want the target caught %v", caught, tt.wantCaught)
+			}
+			if p.hook.hooked != tt.wantHooked || p.hook.retracting != tt.wantRetracted {
+				t.Errorf("hook hooked %v and retracting %v, want %v and %v",
+					p.hook.hooked, p.hook.retracting, tt.wantHooked, tt.wantRetracted)
+			}
+			// An ignored target lets the hook fly on to the ceiling
+			if tt.reaction == HookIgnore && p.hook.pos.Y > target.rect.Y {
+				t.Errorf("hook held at %v, want past the target to the ceiling", p.hook.pos)
+			}
+		})
+	}
+}
+
+func TestHookRidesMovingTarget(t *testing.T) {
+	tw := newTestWorld(t, openFloor)
+	platform := &movingTarget{
+		id:       1,
+		rect:     Rectangle{X: openFloor.X - 16, Y: 300, Width: 64, Height: 16},
+		velocity: Vector2{X: 100, Y: 0},
+		reaction: HookRideTarget,
+	}
+	targets := []*movingTarget{platform}
+
+	p := tw.Player
+	aimFrom(tw, Vector2{X: 0, Y: -1000})
+	p.Hook()
+	tw.stepTargetsUntil(t, targets, func() bool { return p.State() == StateHooked })
+
+	offset := p.hook.pos.X - platform.rect.X
+	start := p.Position().X
+	for i := 0; i < 50; i++ {
+		tw.stepWithTargets(targets)
+	}
+
+	if p.hook.pos.X-platform.rect.X != offset {
+		t.Errorf("hook %v from the target, want %v", p.hook.pos.X-platform.rect.X, offset)
+	}
+	if p.Position().X <= start {
+		t.Errorf("player at %v, want carried right of %v", p.Position().X, start)
+	}
+
+	// The target is gone, the hook lets go
+	tw.stepWithTargets(nil)
+	if p.HookLaunched() || p.State() == StateHooked {
+		t.Error("hook still holds a target that is gone")
+	}
+}
@@ -0,0 +1,64 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type Tile struct {
+	Index      int        `json:"index"`
+	Properties []Property `json:"properties"`
+}
+
+type MapConfiguration struct {
+	Width      int      `json:"width"`
+	Height     int      `json:"height"`
+	TileHeight int      `json:"tileHeight"`
+	TileWidth  int      `json:"tileWidth"`
+	ImagePath  string   `json:"imagePath"`
+	Board      [][]Tile `json:"tiles"`
+}
+
+func NewMapConfiguration(path string) (MapConfiguration, error) {
+	var mc MapConfiguration
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return mc, err
+	}
+
+	err = json.Unmarshal(data, &mc)
+	if err != nil {
+		return mc, fmt.Errorf("invalid map %s: %v", path, err)
+	}
+
+	return mc, nil
+}
+
+func (mc MapConfiguration) Walls() []Rectangle {
+	var walls []Rectangle
+
+	for y := 0; y < len(mc.Board); y++ {
+		for x := 0; x < len(mc.Board[y]); x++ {
+			tileIndex := mc.Board[y][x].Index
+
+			// TODO: Handle properties
+			if tileIndex >= 0 {
+				walls = append(walls, Rectangle{
+					X:      float32(x * mc.TileWidth),
+					Y:      float32(y * mc.TileHeight),
+					Width:  float32(mc.TileWidth),
+					Height: float32(mc.TileHeight),
+				})
+			}
+		}
+	}
+
+	return walls
+}
@@ -1,39 +1,60 @@
-package game
+package sim
 
 import (
 	"math"
-
-	rl "github.com/chunqian/go-raylib/raylib"
 )
 
-func angleFromVectors(v1, v2 rl.Vector2) float64 {
+type Vector2 struct {
+	X, Y float32
+}
+
+type Rectangle struct {
+	X, Y, Width, Height float32
+}
+
+func angleFromVectors(v1, v2 Vector2) float64 {
 	x := v2.X - v1.X
 	y := v2.Y - v1.Y
 
 	return math.Atan2(float64(y), float64(x))
 }
 
-func DirectionVectorFromAngle(angle float64) rl.Vector2 {
-	return rl.Vector2{
+func DirectionVectorFromAngle(angle float64) Vector2 {
+	return Vector2{
 		X: float32(math.Cos(angle)),
 		Y: float32(math.Sin(angle)),
 	}
 }
 
-func DirectionVectorFromVectors(v1, v2 rl.Vector2) rl.Vector2 {
+func DirectionVectorFromVectors(v1, v2 Vector2) Vector2 {
 	return DirectionVectorFromAngle(angleFromVectors(v1, v2))
 }
 
-func LerpVec2(vec rl.Vector2, factor float64) rl.Vector2 {
-	return rl.Vector2{X: vec.X * float32(factor), Y: vec.Y * float32(factor)}
+// snapAngle rounds the angle to the closest of steps directions evenly spread
+// around the circle, the first one pointing right.
+func snapAngle(angle float64, steps int) float64 {
+	step := 2 * math.Pi / float64(steps)
+	return math.Round(angle/step) * step
+}
+
+// LerpVec2 returns the point at factor (0 to 1) on the way from one vector to the other.
+func LerpVec2(from, to Vector2, factor float64) Vector2 {
+	return Vector2{
+		X: from.X + (to.X-from.X)*float32(factor),
+		Y: from.Y + (to.Y-from.Y)*float32(factor),
+	}
+}
+
+func Vector2Distance(v1, v2 Vector2) float32 {
+	return float32(math.Hypot(float64(v2.X-v1.X), float64(v2.Y-v1.Y)))
 }
 
 // See https://github.com/MonoGame/MonoGame/blob/2911bbb3bcc412aedc00c109f3a96bb2480b255f/MonoGame.Framework/Ray.cs#L97
 // Does not works as expected.
-func RayAABBCollision(origin, direction rl.Vector2, aabb rl.Rectangle) (bool, float32) {
+func RayAABBCollision(origin, direction Vector2, aabb Rectangle) (bool, float32) {
 	epsilon := 0.000001
-	box_min := rl.Vector2{X: aabb.X, Y: aabb.Y}
-	box_max := rl.Vector2{X: aabb.X + aabb.Width, Y: aabb.Y + aabb.Height}
+	box_min := Vector2{X: aabb.X, Y: aabb.Y}
+	box_max := Vector2{X: aabb.X + aabb.Width, Y: aabb.Y + aabb.Height}
 	tMin := float32(math.Inf(-1))
 	tMax := float32(math.Inf(1))
 
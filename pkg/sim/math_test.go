@@ -0,0 +1,52 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLerpVec2(t *testing.T) {
+	from := Vector2{X: 10, Y: 100}
+	to := Vector2{X: 30, Y: 60}
+
+	tests := []struct {
+		name   string
+		factor float64
+		want   Vector2
+	}{
+		{name: "start of the step", factor: 0, want: from},
+		{name: "middle of the step", factor: 0.5, want: Vector2{X: 20, Y: 80}},
+		{name: "end of the step", factor: 1, want: to},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LerpVec2(from, to, tt.factor); got != tt.want {
+				t.Errorf("LerpVec2(%v) = %v, want %v", tt.factor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapAngle(t *testing.T) {
+	tests := []struct {
+		name  string
+		angle float64
+		want  float64
+	}{
+		{name: "right", angle: 0.3, want: 0},
+		{name: "down and right", angle: 0.5, want: math.Pi / 4},
+		{name: "down", angle: 1.4, want: math.Pi / 2},
+		{name: "up and left", angle: -2.5, want: -3 * math.Pi / 4},
+		{name: "left from below", angle: 3, want: math.Pi},
+		{name: "left from above", angle: -3, want: -math.Pi},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snapAngle(tt.angle, 8); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("snapAngle(%v, 8) = %v, want %v", tt.angle, got, tt.want)
+			}
+		})
+	}
+}
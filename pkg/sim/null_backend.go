@@ -0,0 +1,29 @@
+package sim
+
+// NullRenderer discards every draw call.
+type NullRenderer struct{}
+
+func (nr NullRenderer) DrawRectangle(rec Rectangle, color Color) {}
+
+func (nr NullRenderer) DrawLine(start, end Vector2, thick float32, color Color) {}
+
+// FixedInput always aims at the same position.
+type FixedInput struct {
+	Aim Vector2
+}
+
+func (fi FixedInput) AimPosition() Vector2 {
+	return fi.Aim
+}
+
+// RunHeadless steps the world the given number of times and returns the stars
+// collected along the way.
+func RunHeadless(w *World, steps int, dt float32) []Star {
+	var collected []Star
+
+	for i := 0; i < steps; i++ {
+		collected = append(collected, w.Step(dt)...)
+	}
+
+	return collected
+}
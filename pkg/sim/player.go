@@ -0,0 +1,737 @@
+package sim
+
+const Friction = 0.80
+const Gravity = 10
+
+const PlayerSpeed = 100
+const PlayerJumpSpeed = 550
+
+// A dash covers this distance in this many seconds, whatever the speed of the player
+const DashDistance = 250
+const DashDuration = 0.15
+
+// Dashes the player can make in the air before landing, by default
+const AirDashes = 1
+
+// Cooldowns are in seconds of simulation time
+const DashCooldown = 0.5
+const PortalCooldown = 0.5
+
+// A jump pressed this long before landing still fires on landing
+const JumpBufferTime = 0.1
+
+// The player can still jump this long after walking off a ledge
+const CoyoteTime = 0.1
+
+// Releasing jump while going up multiplies the upward speed by this factor,
+// so a short press gives a short hop
+const JumpCutFactor = 0.5
+
+// Jumps the player can make in the air before landing, by default
+const AirJumps = 1
+
+// Falling speed of the player sliding down a wall
+const WallSlideSpeed = 150
+
+// Speed given by a jump off a wall, away from the wall and up
+const WallJumpHorizontalSpeed = 2000
+const WallJumpVerticalSpeed = 500
+
+// WallSide tells on which side of the player a wall is touched.
+type WallSide int
+
+const (
+	NoWall WallSide = iota
+	WallLeft
+	WallRight
+)
+
+// away gives the horizontal direction pointing away from the wall.
+func (ws WallSide) away() float32 {
+	switch ws {
+	case WallLeft:
+		return 1
+	case WallRight:
+		return -1
+	}
+
+	return 0
+}
+
+// AbilityStats counts how many times each ability was used.
+type AbilityStats struct {
+	Jumps   int
+	Dashes  int
+	Hooks   int
+	Portals int
+}
+
+type Player struct {
+	pos, lastPos, velocity, lastVelocity, hookVelocity, size Vector2
+	hookLaunched, jumpHeld                                   bool
+	color                                                    Color
+	hook                                                     Hook
+	dashCooldown, portalCooldown                             Timer
+	jumpBuffer, coyoteTime                                   Timer
+	airJumps, airJumpsLeft                                   int
+	wall                                                     WallSide
+	dash                                                     Timer
+	dashVelocity                                             Vector2
+	airDashes, airDashesLeft                                 int
+	state                                                    PlayerState
+	onEnter, onExit                                          map[PlayerState][]StateCallback
+	hookMode                                                 HookMode
+	reel                                                     float32
+	portal                                                   Portal
+	input                                                    Input
+	stats                                                    AbilityStats
+}
+
+func NewPlayer(pos Vector2, input Input) *Player {
+	return &Player{
+		pos:            pos,
+		lastPos:        pos,
+		velocity:       Vector2{X: 0, Y: 0},
+		lastVelocity:   Vector2{X: 0, Y: 0},
+		size:           Vector2{X: 32, Y: 64},
+		color:          Red,
+		dashCooldown:   NewTimer(DashCooldown),
+		portalCooldown: NewTimer(PortalCooldown),
+		jumpBuffer:     NewTimer(JumpBufferTime),
+		coyoteTime:     NewTimer(CoyoteTime),
+		airJumps:       AirJumps,
+		airJumpsLeft:   AirJumps,
+		dash:           NewTimer(DashDuration),
+		airDashes:      AirDashes,
+		airDashesLeft:  AirDashes,
+		state:          StateFalling,
+		hookMode:       HookPull,
+		onEnter:        make(map[PlayerState][]StateCallback),
+		onExit:         make(map[PlayerState][]StateCallback),
+		input:          input,
+	}
+}
+
+func (p Player) Rectangle() Rectangle {
+	// TODO: Avoid creating new rectangle each time if this becomes a performance bottleneck
+	return Rectangle{
+		X:      p.pos.X,
+		Y:      p.pos.Y,
+		Width:  p.size.X,
+		Height: p.size.Y,
+	}
+}
+
+func (p Player) Position() Vector2 {
+	return p.pos
+}
+
+func (p Player) LastPosition() Vector2 {
+	return p.lastPos
+}
+
+func (p Player) Velocity() Vector2 {
+	return p.velocity
+}
+
+func (p Player) LastVelocity() Vector2 {
+	return p.lastVelocity
+}
+
+func (p Player) Size() Vector2 {
+	return p.size
+}
+
+func (p Player) Stats() AbilityStats {
+	return p.stats
+}
+
+// Wall gives the side of the wall the player touches, if any.
+func (p Player) Wall() WallSide {
+	return p.wall
+}
+
+// WallSliding reports if the player slides down a wall. The hook holds the
+// player in place of the wall once it is hooked.
+func (p Player) WallSliding() bool {
+	return p.state == StateWallSliding
+}
+
+// AirJumps gives how many jumps the player can make in the air.
+func (p Player) AirJumps() int {
+	return p.airJumps
+}
+
+// AirJumpsLeft gives how many air jumps are left before landing.
+func (p Player) AirJumpsLeft() int {
+	return p.airJumpsLeft
+}
+
+// SetAirJumps changes how many jumps the player can make in the air.
+func (p *Player) SetAirJumps(count int) {
+	p.airJumps = count
+	p.airJumpsLeft = count
+}
+
+// AirDashes gives how many dashes the player can make in the air.
+func (p Player) AirDashes() int {
+	return p.airDashes
+}
+
+// AirDashesLeft gives how many air dashes are left before landing.
+func (p Player) AirDashesLeft() int {
+	return p.airDashesLeft
+}
+
+// SetAirDashes changes how many dashes the player can make in the air.
+func (p *Player) SetAirDashes(count int) {
+	p.airDashes = count
+	p.airDashesLeft = count
+}
+
+func (p Player) Dashing() bool {
+	return p.state == StateDashing
+}
+
+// refillAirJumps gives the air jumps back, on landing, hooking or going
+// through a portal.
+func (p *Player) refillAirJumps() {
+	p.airJumpsLeft = p.airJumps
+}
+
+// MoveRight pushes the player right, strength goes from 0 to 1 for analog
+// sticks and is 1 for keys.
+func (p *Player) MoveRight(strength float32) {
+	if p.state.Allows(AbilityMove) {
+		p.velocity.X += p.moveSpeed() * strength
+	}
+}
+
+func (p *Player) MoveLeft(strength float32) {
+	if p.state.Allows(AbilityMove) {
+		p.velocity.X -= p.moveSpeed() * strength
+	}
+}
+
+// moveSpeed is lower on a rope, where the speed is kept from step to step.
+func (p Player) moveSpeed() float32 {
+	if p.onRope() {
+		return PlayerSpeed * HookRopeControl
+	}
+
+	return PlayerSpeed
+}
+
+// Jump jumps right away on the ground or during coyote time, against a wall
+// it jumps off the wall and in the air it uses an air jump. Without air jump
+// left the press is buffered and the jump fires if the player lands soon
+// enough.
+func (p *Player) Jump() {
+	p.jumpHeld = true
+
+	switch {
+	case p.canGroundJump():
+		p.groundJump()
+	case p.state.Allows(AbilityWallJump) && p.wall != NoWall:
+		p.wallJump()
+	case p.state.Allows(AbilityAirJump) && p.airJumpsLeft > 0:
+		p.airJumpsLeft--
+		p.launchJump()
+	case p.state.Allows(AbilityAirJump):
+		p.jumpBuffer.Start()
+	}
+}
+
+// ReleaseJump ends the jump early, the longer jump is held the higher it goes.
+func (p *Player) ReleaseJump() {
+	p.jumpHeld = false
+	p.cutJump()
+}
+
+// canGroundJump reports if the player stands on the ground, or left it a
+// moment ago.
+func (p Player) canGroundJump() bool {
+	return p.state.Allows(AbilityJump) || p.state.Allows(AbilityAirJump) && p.coyoteTime.Running()
+}
+
+func (p *Player) groundJump() {
+	p.jumpBuffer.Stop()
+	p.coyoteTime.Stop()
+	p.launchJump()
+
+	// The button was already released when a buffered jump fires
+	if !p.jumpHeld {
+		p.cutJump()
+	}
+}
+
+// wallJump kicks the player off the wall, letting go of the hook.
+func (p *Player) wallJump() {
+	p.dash.Stop()
+	p.StopHook()
+	p.velocity.X = p.wall.away() * WallJumpHorizontalSpeed
+	p.velocity.Y = -WallJumpVerticalSpeed
+	p.wall = NoWall
+	p.stats.Jumps++
+	p.setState(StateJumping)
+}
+
+// launchJump cancels a running dash, keeping its horizontal speed.
+func (p *Player) launchJump() {
+	p.dash.Stop()
+	p.velocity.Y = -PlayerJumpSpeed
+	p.stats.Jumps++
+	p.setState(StateJumping)
+}
+
+func (p *Player) cutJump() {
+	if p.state == StateJumping && p.velocity.Y < 0 {
+		p.velocity.Y *= JumpCutFactor
+	}
+}
+
+// Dash moves the player a fixed distance in one of eight directions, the
+// closest to the aim. Gravity and friction are suspended during the dash.
+func (p *Player) Dash() {
+	if p.dashCooldown.Running() {
+		return
+	}
+
+	switch {
+	case p.state.Allows(AbilityDash):
+		// The jump stays available until the end of a dash from the ground
+		p.coyoteTime.Start()
+	case p.state.Allows(AbilityAirDash) && p.airDashesLeft > 0:
+		p.airDashesLeft--
+	default:
+		return
+	}
+
+	dir := DirectionVectorFromAngle(snapAngle(angleFromVectors(p.pos, p.input.AimPosition()), 8))
+
+	// Dashing into the wall the player slides on would go nowhere, dash away from it
+	if p.WallSliding() && dir.X*p.wall.away() < 0 {
+		dir.X = -dir.X
+	}
+
+	speed := float32(DashDistance / DashDuration)
+	p.dashVelocity = Vector2{X: dir.X * speed, Y: dir.Y * speed}
+	p.dashCooldown.Start()
+	p.dash.Start()
+	p.stats.Dashes++
+	p.setState(StateDashing)
+}
+
+// endDash leaves the player with a normal running speed in the dash direction.
+func (p *Player) endDash() {
+	speed := float32(DashDistance / DashDuration)
+	p.velocity.X = p.dashVelocity.X / speed * PlayerSpeed
+	p.velocity.Y = p.dashVelocity.Y / speed * PlayerSpeed
+}
+
+func (p *Player) Hook() {
+	if p.state.Allows(AbilityHook) && !p.hookLaunched {
+		p.hook = NewHook(*p)
+		p.hookLaunched = true
+		p.stats.Hooks++
+	}
+}
+
+func (p *Player) StopHook() {
+	p.hook.target = 0
+	p.hookLaunched = false
+
+	if p.state == StateHooked {
+		p.setState(p.airborneState())
+	}
+}
+
+// HookLaunched reports if the hook is out, flying or holding on something.
+func (p Player) HookLaunched() bool {
+	return p.hookLaunched
+}
+
+func (p Player) HookMode() HookMode {
+	return p.hookMode
+}
+
+// SetHookMode changes how the hook moves the player from the next hook on.
+func (p *Player) SetHookMode(mode HookMode) {
+	p.hookMode = mode
+}
+
+// ReelIn shortens the rope during the step, in rope mode.
+func (p *Player) ReelIn() {
+	p.reel = -1
+}
+
+// ReelOut lengthens the rope during the step, in rope mode.
+func (p *Player) ReelOut() {
+	p.reel = 1
+}
+
+func (p Player) onRope() bool {
+	return p.state == StateHooked && p.hookMode == HookRope
+}
+
+// latchHook is called when the hook holds on a wall or an entity.
+func (p *Player) latchHook() {
+	p.refillAirJumps()
+	p.hook.length = Vector2Distance(p.pos, p.hook.pos)
+}
+
+// catchWithHook lets the flying hook catch the first entity it touches, it
+// returns the entity to collect right away, if any.
+func (p *Player) catchWithHook(targets []HookTarget) EntityID {
+	if !p.hookLaunched || p.hook.hooked || p.hook.retracting {
+		return 0
+	}
+
+	for _, target := range targets {
+		if !IsColliding(p.hook.Rectangle(), target.Rectangle()) {
+			continue
+		}
+
+		switch target.HookReaction() {
+		case HookPullTarget:
+			p.hook.target = target.ID()
+			p.hook.retracting = true
+		case HookCollectTarget:
+			p.hook.retracting = true
+			return target.ID()
+		case HookRideTarget:
+			rect := target.Rectangle()
+			p.hook.target = target.ID()
+			p.hook.targetOffset = Vector2{X: p.hook.pos.X - rect.X, Y: p.hook.pos.Y - rect.Y}
+			p.hook.velocity = Vector2{X: 0, Y: 0}
+			p.hook.hooked = true
+			p.latchHook()
+		default:
+			continue
+		}
+
+		return 0
+	}
+
+	return 0
+}
+
+// followHookTarget brings a pulled entity along with the hook, or moves the
+// hook with the entity it rides. The hook lets go of entities that are gone.
+func (p *Player) followHookTarget(targets []HookTarget) {
+	if !p.hookLaunched || p.hook.target == 0 {
+		return
+	}
+
+	for _, target := range targets {
+		if target.ID() != p.hook.target {
+			continue
+		}
+
+		switch target.HookReaction() {
+		case HookPullTarget:
+			target.MoveTo(p.hook.pos)
+		case HookRideTarget:
+			rect := target.Rectangle()
+			p.hook.pos = Vector2{X: rect.X + p.hook.targetOffset.X, Y: rect.Y + p.hook.targetOffset.Y}
+		}
+		return
+	}
+
+	if p.hook.hooked {
+		p.StopHook()
+	}
+	p.hook.target = 0
+}
+
+// moveHook makes the flying hook travel, it flies back to the player past
+// HookMaxDistance and disappears once back.
+func (p *Player) moveHook(deltaTime float32) {
+	if p.hook.retracting {
+		if Vector2Distance(p.pos, p.hook.pos) <= HookSpeed*deltaTime {
+			p.StopHook()
+			return
+		}
+
+		dir := DirectionVectorFromVectors(p.hook.pos, p.pos)
+		p.hook.velocity = Vector2{X: dir.X * HookSpeed, Y: dir.Y * HookSpeed}
+	}
+
+	p.hook.pos.X += p.hook.velocity.X * deltaTime
+	p.hook.pos.Y += p.hook.velocity.Y * deltaTime
+
+	if Vector2Distance(p.pos, p.hook.pos) > HookMaxDistance {
+		p.hook.retracting = true
+	}
+}
+
+// pullToHook drags the player toward the hook.
+func (p *Player) pullToHook() {
+	dir := DirectionVectorFromVectors(p.pos, p.hook.pos)
+	p.hookVelocity.X = dir.X * HookHorizontalForce
+	p.hookVelocity.Y = dir.Y * HookVerticalForce
+
+	// The hook as more power to drag you up then down. This makes it easier to get on top of a platform
+	if p.hookVelocity.Y > 0 {
+		p.hookVelocity.Y *= 0.3
+	}
+
+	// The hook will boost it's power if the player wants to move on that direction.
+	// Otherwise it will slow down everything a bit
+	if p.hookVelocity.X < 0 && p.velocity.X < 0 || p.hookVelocity.X > 0 && p.velocity.X > 0 {
+		p.hookVelocity.X *= 0.95
+	} else {
+		p.hookVelocity.X *= 0.75
+	}
+
+	// Apply hook physics
+	p.velocity.X += p.hookVelocity.X
+	p.velocity.Y += p.hookVelocity.Y
+}
+
+// holdOnRope keeps the player within the rope length of the hook, removing
+// the speed stretching the rope so the player swings around the hook.
+func (p *Player) holdOnRope(reel, deltaTime float32) {
+	p.hook.length += reel * HookReelSpeed * deltaTime
+	if p.hook.length < HookMinLength {
+		p.hook.length = HookMinLength
+	} else if p.hook.length > HookMaxDistance {
+		p.hook.length = HookMaxDistance
+	}
+
+	distance := Vector2Distance(p.hook.pos, p.pos)
+	if distance <= p.hook.length {
+		return
+	}
+
+	dir := Vector2{X: (p.pos.X - p.hook.pos.X) / distance, Y: (p.pos.Y - p.hook.pos.Y) / distance}
+	p.pos.X = p.hook.pos.X + dir.X*p.hook.length
+	p.pos.Y = p.hook.pos.Y + dir.Y*p.hook.length
+
+	stretch := p.velocity.X*dir.X + p.velocity.Y*dir.Y
+	if stretch > 0 {
+		p.velocity.X -= dir.X * stretch
+		p.velocity.Y -= dir.Y * stretch
+	}
+}
+
+func (p *Player) FirePortal(walls []Rectangle) {
+	if p.state.Allows(AbilityPortal) && !p.portalCooldown.Running() {
+		p.portalCooldown.Start()
+		portal_box := p.Rectangle()
+		dir := DirectionVectorFromVectors(p.pos, p.input.AimPosition())
+		velocity := Vector2{X: dir.X * 10, Y: dir.Y * 10}
+
+		// TODO: impl Ray -> AABB collision
+		for j := 0; j < 10000; j++ {
+			for i := 0; i < len(walls); i++ {
+				if IsColliding(portal_box, walls[i]) {
+					direction := CollisionDirection(portal_box, walls[i])
+					SolvePortalCollision(&portal_box, walls[i], direction)
+					p.portal.Trigger(Vector2{X: portal_box.X, Y: portal_box.Y})
+					p.stats.Portals++
+					return
+				}
+			}
+
+			portal_box.X += velocity.X
+			portal_box.Y += velocity.Y
+		}
+	}
+}
+
+func (p *Player) Teleport(pos Vector2) {
+	p.pos.X = pos.X
+	p.pos.Y = pos.Y
+
+	// Do not interpolate the jump through the portal
+	p.lastPos = p.pos
+	p.refillAirJumps()
+	p.setState(StateTeleporting)
+}
+
+// SaveState keeps the current physics state as the previous state used to
+// interpolate drawing between two simulation steps.
+func (p *Player) SaveState() {
+	p.lastPos = p.pos
+	p.lastVelocity = p.velocity
+
+	if p.hookLaunched {
+		p.hook.SaveState()
+	}
+}
+
+// InterpolatedPosition returns the position between the last two simulation steps.
+func (p Player) InterpolatedPosition(factor float64) Vector2 {
+	return LerpVec2(p.lastPos, p.pos, factor)
+}
+
+// Note: Hook physics is heavily inspired by Teeworlds, see:
+// https://github.com/teeworlds/teeworlds/blob/b0c4c7002b28ee195934281e524f163f7ed30c59/src/game/gamecore.cpp#L263
+func (p *Player) Update(deltaTime float32) {
+	p.dashCooldown.Tick(float64(deltaTime))
+	p.portalCooldown.Tick(float64(deltaTime))
+	p.jumpBuffer.Tick(float64(deltaTime))
+	if p.state != StateDashing {
+		p.coyoteTime.Tick(float64(deltaTime))
+	}
+
+	// Fire a jump buffered before landing
+	if p.jumpBuffer.Running() && p.state.Allows(AbilityJump) {
+		p.groundJump()
+	}
+
+	if p.hookLaunched && !p.hook.hooked {
+		p.moveHook(deltaTime)
+	}
+
+	// Reeling only lasts while it is held
+	reel := p.reel
+	p.reel = 0
+
+	if p.dash.Running() {
+		p.velocity = p.dashVelocity
+		p.pos.X += p.velocity.X * deltaTime
+		p.pos.Y += p.velocity.Y * deltaTime
+
+		// Ends after its last step so it covers the whole dash distance
+		if p.dash.Tick(float64(deltaTime)) {
+			p.endDash()
+		}
+		return
+	}
+
+	if p.state == StateHooked && p.hookMode != HookRope {
+		p.pullToHook()
+	}
+
+	// Run natural forces
+	if p.onRope() {
+		p.velocity.X *= HookRopeFriction
+	} else {
+		p.velocity.X *= Friction
+	}
+	p.velocity.Y += Gravity
+
+	if p.state == StateWallSliding && p.velocity.Y > WallSlideSpeed {
+		p.velocity.Y = WallSlideSpeed
+	}
+
+	// Apply velocity
+	p.pos.X += p.velocity.X * deltaTime
+	p.pos.Y += p.velocity.Y * deltaTime
+
+	if p.onRope() {
+		p.holdOnRope(reel, deltaTime)
+	}
+}
+
+func (p *Player) checkAndHandleCollisions(walls []Rectangle) {
+	for i := 0; i < len(walls); i++ {
+		if IsColliding(p.Rectangle(), walls[i]) {
+			direction := CollisionDirection(p.Rectangle(), walls[i])
+			p.SolveCollision(walls[i], direction)
+		}
+
+		if p.hookLaunched && !p.hook.retracting {
+			if IsColliding(p.hook.Rectangle(), walls[i]) {
+				latching := !p.hook.hooked
+
+				direction := CollisionDirection(p.hook.Rectangle(), walls[i])
+				p.hook.SolveCollision(walls[i], direction)
+
+				if latching {
+					p.latchHook()
+				}
+			}
+		}
+	}
+
+	// The player stays teleporting until the next step
+	if p.portal.status == "ended" && IsColliding(p.Rectangle(), p.portal.EntryRectangle()) {
+		p.StopHook()
+		p.Teleport(p.portal.exit_pos)
+		return
+	}
+
+	p.wall = touchedWall(p.Rectangle(), walls)
+
+	// Walked off a ledge, the jump stays available for a moment
+	wasOnGround := p.state.Allows(AbilityJump)
+	p.updateState(touchesFloor(p.Rectangle(), walls))
+	if wasOnGround && p.state == StateFalling {
+		p.coyoteTime.Start()
+	}
+}
+
+// touchedWall looks for a wall right next to either side of the body.
+func touchedWall(body Rectangle, walls []Rectangle) WallSide {
+	left := body
+	left.X--
+	right := body
+	right.X++
+
+	for i := 0; i < len(walls); i++ {
+		if IsColliding(left, walls[i]) {
+			return WallLeft
+		}
+		if IsColliding(right, walls[i]) {
+			return WallRight
+		}
+	}
+
+	return NoWall
+}
+
+// touchesFloor looks for a wall right under the body.
+func touchesFloor(body Rectangle, walls []Rectangle) bool {
+	body.Y++
+
+	for i := 0; i < len(walls); i++ {
+		if IsColliding(body, walls[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Player) SolveCollision(wall Rectangle, direction string) {
+	p.color = Red
+
+	switch direction {
+	case "bottom":
+		p.coyoteTime.Stop()
+		p.refillAirJumps()
+		p.airDashesLeft = p.airDashes
+		p.pos.Y = wall.Y - p.size.Y
+		p.velocity.Y = 0
+		p.dashVelocity.Y = 0
+	case "right":
+		p.pos.X = wall.X + wall.Width
+		p.velocity.X = 0
+		p.dashVelocity.X = 0
+	case "left":
+		p.pos.X = wall.X - p.size.X
+		p.velocity.X = 0
+		p.dashVelocity.X = 0
+	case "top":
+		p.pos.Y = wall.Y + wall.Height
+		p.velocity.Y = 0
+		p.dashVelocity.Y = 0
+	}
+}
+
+func (p Player) Draw(r Renderer, factor float64) {
+	p.portal.Draw(r)
+
+	pos := p.InterpolatedPosition(factor)
+	r.DrawRectangle(Rectangle{X: pos.X, Y: pos.Y, Width: p.size.X, Height: p.size.Y}, p.color)
+
+	if p.hookLaunched {
+		hookPos := p.hook.InterpolatedPosition(factor)
+		r.DrawRectangle(Rectangle{X: hookPos.X, Y: hookPos.Y, Width: p.hook.size.X, Height: p.hook.size.Y}, p.hook.color)
+
+		r.DrawLine(pos, hookPos, 5, Black)
+	}
+}
@@ -0,0 +1,144 @@
+package sim
+
+import (
+	"fmt"
+)
+
+// Horizontal speed above which a player on the ground is running
+const RunningSpeed = 10
+
+// PlayerState is what the player is doing. It decides which abilities can be
+// used, and listeners are told when the player enters or leaves a state.
+type PlayerState int
+
+const (
+	StateIdle PlayerState = iota
+	StateRunning
+	StateJumping
+	StateFalling
+	StateWallSliding
+	StateDashing
+	StateHooked
+	StateTeleporting
+	stateCount
+)
+
+var playerStateNames = [stateCount]string{
+	StateIdle:        "idle",
+	StateRunning:     "running",
+	StateJumping:     "jumping",
+	StateFalling:     "falling",
+	StateWallSliding: "wall_sliding",
+	StateDashing:     "dashing",
+	StateHooked:      "hooked",
+	StateTeleporting: "teleporting",
+}
+
+func (s PlayerState) String() string {
+	if s < 0 || s >= stateCount {
+		return fmt.Sprintf("PlayerState(%d)", int(s))
+	}
+
+	return playerStateNames[s]
+}
+
+// Ability is a set of player abilities, each one a bit.
+type Ability int
+
+const (
+	AbilityMove Ability = 1 << iota
+	// Jumps from the ground, also allowed for a moment after leaving it
+	AbilityJump
+	AbilityAirJump
+	AbilityWallJump
+	// Dashes from the ground, the ones in the air are counted
+	AbilityDash
+	AbilityAirDash
+	AbilityHook
+	AbilityPortal
+
+	groundAbilities = AbilityMove | AbilityJump | AbilityDash | AbilityHook | AbilityPortal
+	airAbilities    = AbilityMove | AbilityAirJump | AbilityWallJump | AbilityAirDash | AbilityHook | AbilityPortal
+)
+
+// Abilities allowed in each state. A wall slide always ends with a wall jump,
+// a dash can only be cancelled by a jump, the hook has to let go before a
+// dash and nothing can be done on the step the player goes through a portal.
+var stateAbilities = [stateCount]Ability{
+	StateIdle:        groundAbilities,
+	StateRunning:     groundAbilities,
+	StateJumping:     airAbilities,
+	StateFalling:     airAbilities,
+	StateWallSliding: airAbilities &^ AbilityAirJump,
+	StateDashing:     AbilityAirJump | AbilityWallJump | AbilityHook | AbilityPortal,
+	StateHooked:      airAbilities &^ AbilityAirDash,
+	StateTeleporting: 0,
+}
+
+// Allows reports if every given ability can be used in the state.
+func (s PlayerState) Allows(abilities Ability) bool {
+	return stateAbilities[s]&abilities == abilities
+}
+
+// StateCallback is called on a state change with the state on the other side
+// of the transition, the previous one on enter and the next one on exit.
+type StateCallback func(other PlayerState)
+
+func (p Player) State() PlayerState {
+	return p.state
+}
+
+// OnEnter registers a callback called each time the player enters the state.
+func (p *Player) OnEnter(state PlayerState, callback StateCallback) {
+	p.onEnter[state] = append(p.onEnter[state], callback)
+}
+
+// OnExit registers a callback called each time the player leaves the state.
+func (p *Player) OnExit(state PlayerState, callback StateCallback) {
+	p.onExit[state] = append(p.onExit[state], callback)
+}
+
+func (p *Player) setState(state PlayerState) {
+	if state == p.state {
+		return
+	}
+
+	previous := p.state
+	for _, callback := range p.onExit[previous] {
+		callback(state)
+	}
+
+	p.state = state
+	for _, callback := range p.onEnter[state] {
+		callback(previous)
+	}
+}
+
+// updateState finds the state from the physics of the step that just ended.
+func (p *Player) updateState(onGround bool) {
+	switch {
+	case p.dash.Running():
+		p.setState(StateDashing)
+	case p.hookLaunched && p.hook.hooked:
+		p.setState(StateHooked)
+	case onGround && (p.velocity.X > RunningSpeed || p.velocity.X < -RunningSpeed):
+		p.setState(StateRunning)
+	case onGround:
+		p.setState(StateIdle)
+	default:
+		p.setState(p.airborneState())
+	}
+}
+
+// airborneState is the state of a player in the air, rising along a wall
+// is still a jump.
+func (p Player) airborneState() PlayerState {
+	switch {
+	case p.velocity.Y < 0:
+		return StateJumping
+	case p.wall != NoWall:
+		return StateWallSliding
+	default:
+		return StateFalling
+	}
+}
@@ -0,0 +1,186 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+)
+
+// hookCeiling hooks the ceiling above the player and waits for it to hold.
+func hookCeiling(t *testing.T, tw testWorld) {
+	tw.input.Aim = Vector2{X: tw.Player.pos.X, Y: 0}
+	tw.Player.Hook()
+	tw.stepUntil(t, nil, func() bool { return tw.Player.State() == StateHooked })
+}
+
+func TestPlayerStates(t *testing.T) {
+	tests := []struct {
+		name  string
+		pos   Vector2
+		enter func(t *testing.T, tw testWorld)
+		want  PlayerState
+	}{
+		{name: "resting", pos: openFloor, enter: func(*testing.T, testWorld) {}, want: StateIdle},
+		{
+			name: "running",
+			pos:  openFloor,
+			enter: func(t *testing.T, tw testWorld) {
+				for i := 0; i < 5; i++ {
+					tw.Player.MoveRight(1)
+					tw.run(1)
+				}
+			},
+			want: StateRunning,
+		},
+		{
+			name:  "jumping",
+			pos:   openFloor,
+			enter: func(t *testing.T, tw testWorld) { tw.Player.Jump(); tw.run(1) },
+			want:  StateJumping,
+		},
+		{
+			name:  "rising along a wall",
+			pos:   leftWall,
+			enter: func(t *testing.T, tw testWorld) { tw.Player.Jump(); tw.run(5) },
+			want:  StateJumping,
+		},
+		{
+			name:  "falling",
+			pos:   openFloor,
+			enter: func(t *testing.T, tw testWorld) { dropAt(tw, Vector2{X: openFloor.X, Y: 300}) },
+			want:  StateFalling,
+		},
+		{
+			name:  "sliding down a wall",
+			pos:   openFloor,
+			enter: func(t *testing.T, tw testWorld) { dropAt(tw, besideLeftWall) },
+			want:  StateWallSliding,
+		},
+		{
+			name:  "dashing",
+			pos:   openFloor,
+			enter: func(t *testing.T, tw testWorld) { aimFrom(tw, Vector2{X: 100, Y: 0}); tw.Player.Dash(); tw.run(1) },
+			want:  StateDashing,
+		},
+		{name: "hooked", pos: openFloor, enter: hookCeiling, want: StateHooked},
+		{
+			name:  "teleporting",
+			pos:   openFloor,
+			enter: func(t *testing.T, tw testWorld) { tw.Player.Teleport(Vector2{X: openFloor.X, Y: 300}) },
+			want:  StateTeleporting,
+		},
+		{
+			name: "out of a portal",
+			pos:  openFloor,
+			enter: func(t *testing.T, tw testWorld) {
+				tw.Player.Teleport(Vector2{X: openFloor.X, Y: 300})
+				tw.run(1)
+			},
+			want: StateFalling,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, tt.pos)
+			tt.enter(t, tw)
+
+			if got := tw.Player.State(); got != tt.want {
+				t.Errorf("player is %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStateCallbacks(t *testing.T) {
+	tw := newTestWorld(t, openFloor)
+	p := tw.Player
+
+	// Each callback sees the state the player is in once the change is made
+	var transitions []string
+	record := func(name string) StateCallback {
+		return func(other PlayerState) {
+			transitions = append(transitions, name+" "+other.String()+" in "+p.State().String())
+		}
+	}
+	p.OnExit(StateIdle, record("exit idle to"))
+	p.OnEnter(StateJumping, record("enter jumping from"))
+	p.OnExit(StateJumping, record("exit jumping to"))
+	p.OnEnter(StateIdle, record("enter idle from"))
+
+	p.Jump()
+	tw.stepUntil(t, nil, func() bool { return p.State() == StateIdle })
+
+	want := []string{
+		"exit idle to jumping in idle",
+		"enter jumping from idle in jumping",
+		"exit jumping to falling in jumping",
+		"enter idle from falling in idle",
+	}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Errorf("transitions %q, want %q", transitions, want)
+	}
+}
+
+func TestStateBlocksAbilities(t *testing.T) {
+	tests := []struct {
+		name  string
+		pos   Vector2
+		enter func(t *testing.T, tw testWorld)
+		use   func(tw testWorld)
+	}{
+		{
+			name:  "hooked player dashing",
+			pos:   openFloor,
+			enter: hookCeiling,
+			use:   func(tw testWorld) { tw.Player.Dash() },
+		},
+		{
+			name: "dashing player moving",
+			pos:  openFloor,
+			enter: func(t *testing.T, tw testWorld) {
+				aimFrom(tw, Vector2{X: 0, Y: -100})
+				tw.Player.Dash()
+				tw.run(5)
+			},
+			use: func(tw testWorld) { tw.Player.MoveRight(1) },
+		},
+		{
+			// Rising during an upward dash is not a jump, releasing jump does not cut it
+			name: "dashing player releasing jump",
+			pos:  openFloor,
+			enter: func(t *testing.T, tw testWorld) {
+				aimFrom(tw, Vector2{X: 0, Y: -100})
+				tw.Player.Dash()
+				tw.run(5)
+			},
+			use: func(tw testWorld) { tw.Player.ReleaseJump() },
+		},
+		{
+			name:  "teleporting player",
+			pos:   openFloor,
+			enter: func(t *testing.T, tw testWorld) { tw.Player.Teleport(Vector2{X: openFloor.X, Y: 300}) },
+			use: func(tw testWorld) {
+				tw.Player.Jump()
+				tw.Player.Dash()
+				tw.Player.Hook()
+				tw.Player.FirePortal(tw.Walls)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, tt.pos)
+			tt.enter(t, tw)
+
+			p := tw.Player
+			state, velocity, stats, hooked := p.State(), p.Velocity(), p.Stats(), p.HookLaunched()
+			tt.use(tw)
+
+			if p.State() != state || p.Velocity() != velocity || p.Stats() != stats || p.HookLaunched() != hooked {
+				t.Errorf("player went from %v at %v, %+v to %v at %v, %+v",
+					state, velocity, stats, p.State(), p.Velocity(), p.Stats())
+			}
+		})
+	}
+}
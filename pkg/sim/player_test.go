@@ -0,0 +1,446 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+// Spots of the test map: a floor with nothing above it, and the left end of a
+// ledge with a drop below
+var openFloor = Vector2{X: 896, Y: 608}
+var ledge = Vector2{X: 256, Y: 416}
+
+// Steps a player lifted to fallHeight above the open floor falls before landing
+const fallHeight = 308
+const fallSteps = 78
+
+// fallOnOpenFloor lifts the player above the open floor and lets it fall for
+// the given number of steps.
+func fallOnOpenFloor(tw testWorld, steps int) {
+	tw.Player.pos.Y = openFloor.Y - fallHeight
+	tw.run(steps)
+}
+
+// walkOffLedge walks the player left until it leaves the ledge.
+func walkOffLedge(t *testing.T, tw testWorld) {
+	tw.stepUntil(t, func() { tw.Player.MoveLeft(1) }, func() bool { return tw.Player.State() == StateFalling })
+}
+
+func TestJumpHeight(t *testing.T) {
+	tests := []struct {
+		name string
+		// Steps jump is held for, 0 to keep holding it
+		holdSteps int
+		wantRise  float64
+	}{
+		{name: "held", holdSteps: 0, wantRise: 148.5},
+		{name: "tapped", holdSteps: 1, wantRise: 36.45},
+		{name: "released on the way up", holdSteps: 10, wantRise: 70.3},
+		{name: "released after the top", holdSteps: 100, wantRise: 148.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			p := tw.Player
+
+			p.Jump()
+			top := p.pos.Y
+			for step := 1; step < 200; step++ {
+				if step == tt.holdSteps {
+					p.ReleaseJump()
+				}
+				tw.run(1)
+
+				if p.pos.Y < top {
+					top = p.pos.Y
+				}
+			}
+
+			if rise := float64(openFloor.Y - top); math.Abs(rise-tt.wantRise) > 1 {
+				t.Errorf("jump rose %v, want %v", rise, tt.wantRise)
+			}
+			if p.pos != openFloor {
+				t.Errorf("player landed at %v, want back on %v", p.pos, openFloor)
+			}
+		})
+	}
+}
+
+func TestJumpTiming(t *testing.T) {
+	tests := []struct {
+		name     string
+		pos      Vector2
+		airJumps int
+		setup    func(t *testing.T, tw testWorld)
+		// Jumps after pressing jump once and waiting for a landing
+		wantJumps int
+	}{
+		{
+			name:      "on the ground",
+			pos:       openFloor,
+			wantJumps: 1,
+		},
+		{
+			name:      "buffered before landing",
+			pos:       openFloor,
+			setup:     func(t *testing.T, tw testWorld) { fallOnOpenFloor(tw, fallSteps-5) },
+			wantJumps: 1,
+		},
+		{
+			name:      "pressed too early",
+			pos:       openFloor,
+			setup:     func(t *testing.T, tw testWorld) { fallOnOpenFloor(tw, fallSteps-20) },
+			wantJumps: 0,
+		},
+		{
+			name: "coyote time",
+			pos:  ledge,
+			setup: func(t *testing.T, tw testWorld) {
+				walkOffLedge(t, tw)
+				tw.run(5)
+			},
+			wantJumps: 1,
+		},
+		{
+			name: "after coyote time",
+			pos:  ledge,
+			setup: func(t *testing.T, tw testWorld) {
+				walkOffLedge(t, tw)
+				tw.run(15)
+			},
+			wantJumps: 0,
+		},
+		{
+			name:     "air jump after coyote time",
+			pos:      ledge,
+			airJumps: 1,
+			setup: func(t *testing.T, tw testWorld) {
+				walkOffLedge(t, tw)
+				tw.run(15)
+			},
+			wantJumps: 1,
+		},
+		{
+			name:      "air jump instead of a buffered one",
+			pos:       openFloor,
+			airJumps:  1,
+			setup:     func(t *testing.T, tw testWorld) { fallOnOpenFloor(tw, fallSteps-5) },
+			wantJumps: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, tt.pos)
+			tw.Player.SetAirJumps(tt.airJumps)
+			if tt.setup != nil {
+				tt.setup(t, tw)
+			}
+
+			tw.Player.Jump()
+			tw.run(30)
+
+			if jumps := tw.Player.Stats().Jumps; jumps != tt.wantJumps {
+				t.Errorf("jumped %v times, want %v", jumps, tt.wantJumps)
+			}
+		})
+	}
+}
+
+func TestGroundJumpIsNotBuffered(t *testing.T) {
+	tw := newTestWorld(t, openFloor)
+	p := tw.Player
+
+	p.Jump()
+
+	if p.velocity.Y != -PlayerJumpSpeed {
+		t.Errorf("player goes up at %v, want %v", -p.velocity.Y, PlayerJumpSpeed)
+	}
+	if p.jumpBuffer.Running() {
+		t.Error("jump from the ground also buffered")
+	}
+}
+
+func TestAirJumps(t *testing.T) {
+	tests := []struct {
+		name      string
+		airJumps  int
+		presses   int
+		wantJumps int
+	}{
+		{name: "none", airJumps: 0, presses: 3, wantJumps: 1},
+		{name: "one", airJumps: 1, presses: 3, wantJumps: 2},
+		{name: "two", airJumps: 2, presses: 3, wantJumps: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			tw.Player.SetAirJumps(tt.airJumps)
+
+			for i := 0; i < tt.presses; i++ {
+				tw.Player.Jump()
+				tw.Player.ReleaseJump()
+				tw.run(10)
+			}
+
+			if jumps := tw.Player.Stats().Jumps; jumps != tt.wantJumps {
+				t.Errorf("jumped %v times, want %v", jumps, tt.wantJumps)
+			}
+		})
+	}
+}
+
+func TestAirJumpRefill(t *testing.T) {
+	tests := []struct {
+		name   string
+		refill func(t *testing.T, tw testWorld)
+	}{
+		{
+			name: "landing",
+			refill: func(t *testing.T, tw testWorld) {
+				tw.stepUntil(t, nil, func() bool { return tw.Player.State().Allows(AbilityJump) })
+			},
+		},
+		{
+			name: "hooking",
+			refill: func(t *testing.T, tw testWorld) {
+				tw.input.Aim = Vector2{X: openFloor.X, Y: 0}
+				tw.Player.Hook()
+				tw.stepUntil(t, nil, func() bool { return tw.Player.hook.hooked })
+			},
+		},
+		{
+			name: "going through a portal",
+			refill: func(t *testing.T, tw testWorld) {
+				tw.Player.Teleport(Vector2{X: openFloor.X, Y: 300})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			tw.Player.Jump()
+			tw.run(10)
+			tw.Player.Jump()
+
+			if left := tw.Player.AirJumpsLeft(); left != 0 {
+				t.Fatalf("%v air jumps left after using one, want 0", left)
+			}
+
+			tt.refill(t, tw)
+
+			if left := tw.Player.AirJumpsLeft(); left != AirJumps {
+				t.Errorf("%v air jumps left, want %v", left, AirJumps)
+			}
+		})
+	}
+}
+
+// Spots of the test map in the air right next to the left and right walls,
+// and on the floor against the left wall
+var besideLeftWall = Vector2{X: 32, Y: 330}
+var besideRightWall = Vector2{X: 1216, Y: 330}
+var leftWall = Vector2{X: 32, Y: 480}
+
+// dropAt puts the player at pos, at rest, and lets it fall past coyote time.
+func dropAt(tw testWorld, pos Vector2) {
+	tw.Player.pos = pos
+	tw.Player.velocity = Vector2{}
+	tw.run(20)
+}
+
+func TestWallSlide(t *testing.T) {
+	tests := []struct {
+		name        string
+		pos         Vector2
+		wantWall    WallSide
+		wantSliding bool
+	}{
+		{name: "along the left wall", pos: besideLeftWall, wantWall: WallLeft, wantSliding: true},
+		{name: "along the right wall", pos: besideRightWall, wantWall: WallRight, wantSliding: true},
+		{name: "away from walls", pos: Vector2{X: openFloor.X, Y: 300}, wantWall: NoWall, wantSliding: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			dropAt(tw, tt.pos)
+
+			p := tw.Player
+			if p.Wall() != tt.wantWall || p.WallSliding() != tt.wantSliding {
+				t.Errorf("wall = %v sliding = %v, want %v and %v", p.Wall(), p.WallSliding(), tt.wantWall, tt.wantSliding)
+			}
+			if tt.wantSliding && p.velocity.Y > WallSlideSpeed {
+				t.Errorf("slides down at %v, want at most %v", p.velocity.Y, WallSlideSpeed)
+			}
+			if !tt.wantSliding && p.velocity.Y <= WallSlideSpeed {
+				t.Errorf("falls at %v, want faster than a slide", p.velocity.Y)
+			}
+		})
+	}
+}
+
+func TestWallJump(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  Vector2
+		// Horizontal direction of the jump, 0 for a straight jump
+		wantDirection float32
+		wantAirJumps  int
+	}{
+		{name: "off the left wall", pos: besideLeftWall, wantDirection: 1, wantAirJumps: AirJumps},
+		{name: "off the right wall", pos: besideRightWall, wantDirection: -1, wantAirJumps: AirJumps},
+		{name: "on the floor against a wall", pos: leftWall, wantDirection: 0, wantAirJumps: AirJumps},
+		{name: "away from walls", pos: Vector2{X: openFloor.X, Y: 300}, wantDirection: 0, wantAirJumps: AirJumps - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			dropAt(tw, tt.pos)
+
+			p := tw.Player
+			p.Jump()
+
+			if p.velocity.Y >= 0 || p.Stats().Jumps != 1 {
+				t.Fatalf("velocity %v after %v jumps, want going up after 1", p.velocity, p.Stats().Jumps)
+			}
+			if direction := p.velocity.X * tt.wantDirection; tt.wantDirection != 0 && direction != WallJumpHorizontalSpeed {
+				t.Errorf("jumped off at %v, want %v", p.velocity.X, tt.wantDirection*WallJumpHorizontalSpeed)
+			}
+			if tt.wantDirection == 0 && p.velocity.X != 0 {
+				t.Errorf("jumped sideways at %v, want straight up", p.velocity.X)
+			}
+			if p.AirJumpsLeft() != tt.wantAirJumps {
+				t.Errorf("%v air jumps left, want %v", p.AirJumpsLeft(), tt.wantAirJumps)
+			}
+		})
+	}
+}
+
+// Steps of a whole dash
+const dashSteps = 15
+
+// aimFrom points the input at an offset from the player.
+func aimFrom(tw testWorld, offset Vector2) {
+	tw.input.Aim = Vector2{X: tw.Player.pos.X + offset.X, Y: tw.Player.pos.Y + offset.Y}
+}
+
+func TestDashDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		aim  Vector2
+		want Vector2
+	}{
+		{name: "right", aim: Vector2{X: 100, Y: 0}, want: Vector2{X: DashDistance, Y: 0}},
+		{name: "snapped to right", aim: Vector2{X: 100, Y: 30}, want: Vector2{X: DashDistance, Y: 0}},
+		{name: "left", aim: Vector2{X: -100, Y: -20}, want: Vector2{X: -DashDistance, Y: 0}},
+		{name: "up", aim: Vector2{X: 10, Y: -100}, want: Vector2{X: 0, Y: -DashDistance}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			p := tw.Player
+			aimFrom(tw, tt.aim)
+
+			p.Dash()
+			tw.run(dashSteps)
+
+			if p.Dashing() {
+				t.Fatalf("still dashing after %v steps", dashSteps)
+			}
+
+			moved := Vector2{X: p.pos.X - openFloor.X, Y: p.pos.Y - openFloor.Y}
+			if Vector2Distance(moved, tt.want) > 1 {
+				t.Errorf("dash moved %v, want %v", moved, tt.want)
+			}
+		})
+	}
+}
+
+func TestDashLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  Vector2
+		// Air dashes available, and dashes tried one cooldown apart
+		airDashes   int
+		tries       int
+		wantDashes  int
+		wantAirLeft int
+	}{
+		{name: "on the ground", pos: openFloor, airDashes: 0, tries: 1, wantDashes: 1, wantAirLeft: 0},
+		{name: "in the air", pos: Vector2{X: openFloor.X, Y: 400}, airDashes: 1, tries: 1, wantDashes: 1, wantAirLeft: 0},
+		{name: "air dashes used up", pos: Vector2{X: openFloor.X, Y: 400}, airDashes: 1, tries: 2, wantDashes: 1, wantAirLeft: 0},
+		{name: "no air dash", pos: Vector2{X: openFloor.X, Y: 400}, airDashes: 0, tries: 1, wantDashes: 0, wantAirLeft: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			dropAt(tw, tt.pos)
+			p := tw.Player
+			p.SetAirDashes(tt.airDashes)
+			aimFrom(tw, Vector2{X: 0, Y: -100})
+
+			for i := 0; i < tt.tries; i++ {
+				p.Dash()
+				tw.run(int(DashCooldown / testStep))
+			}
+
+			if p.Stats().Dashes != tt.wantDashes || p.AirDashesLeft() != tt.wantAirLeft {
+				t.Errorf("dashed %v times with %v air dashes left, want %v with %v left",
+					p.Stats().Dashes, p.AirDashesLeft(), tt.wantDashes, tt.wantAirLeft)
+			}
+		})
+	}
+}
+
+func TestJumpDuringDash(t *testing.T) {
+	tests := []struct {
+		name string
+		aim  Vector2
+		// Steps between the dash and the jump
+		wait          int
+		wantJumps     int
+		wantAirJumps  int
+		wantDashing   bool
+		wantGoingUp   bool
+		wantMovingFar bool
+	}{
+		{name: "cancels the dash", aim: Vector2{X: 100, Y: 0}, wait: 5, wantJumps: 1, wantAirJumps: 0, wantGoingUp: true, wantMovingFar: true},
+		{name: "dash up from the ground keeps the jump", aim: Vector2{X: 0, Y: -100}, wait: dashSteps + 5, wantJumps: 1, wantAirJumps: 0, wantGoingUp: true},
+		{name: "jump gone after coyote time", aim: Vector2{X: 0, Y: -100}, wait: dashSteps + 20, wantJumps: 0, wantAirJumps: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, openFloor)
+			p := tw.Player
+			p.SetAirJumps(0)
+			aimFrom(tw, tt.aim)
+
+			p.Dash()
+			tw.run(tt.wait)
+			p.Jump()
+
+			if p.Stats().Jumps != tt.wantJumps || p.AirJumpsLeft() != tt.wantAirJumps {
+				t.Errorf("jumped %v times with %v air jumps left, want %v with %v left",
+					p.Stats().Jumps, p.AirJumpsLeft(), tt.wantJumps, tt.wantAirJumps)
+			}
+			if p.Dashing() != tt.wantDashing {
+				t.Errorf("dashing = %v, want %v", p.Dashing(), tt.wantDashing)
+			}
+			if goingUp := p.velocity.Y < 0; goingUp != tt.wantGoingUp {
+				t.Errorf("going up = %v, want %v", goingUp, tt.wantGoingUp)
+			}
+			// A jump out of a dash keeps the dash speed
+			if movingFar := p.velocity.X > PlayerSpeed; movingFar != tt.wantMovingFar {
+				t.Errorf("horizontal speed %v, want faster than running %v", p.velocity.X, tt.wantMovingFar)
+			}
+		})
+	}
+}
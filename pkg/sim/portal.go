@@ -0,0 +1,62 @@
+package sim
+
+const PortalWidth = 32
+const PortalHeight = 32
+
+type Portal struct {
+	entry_pos, exit_pos Vector2
+	status              string
+}
+
+func (p *Portal) Trigger(pos Vector2) {
+	if p.status == "triggered" {
+		if !IsColliding(p.EntryRectangle(), Rectangle{X: pos.X, Y: pos.Y, Width: PortalWidth, Height: PortalHeight}) {
+			p.exit_pos = pos
+			p.status = "ended"
+		}
+	} else {
+		p.entry_pos = pos
+		p.status = "triggered"
+	}
+}
+
+func (p Portal) EntryRectangle() Rectangle {
+	return Rectangle{
+		X:      p.entry_pos.X,
+		Y:      p.entry_pos.Y,
+		Width:  PortalWidth,
+		Height: PortalHeight,
+	}
+}
+
+func (p Portal) ExitRectangle() Rectangle {
+	return Rectangle{
+		X:      p.exit_pos.X,
+		Y:      p.exit_pos.Y,
+		Width:  PortalWidth,
+		Height: PortalHeight,
+	}
+}
+
+func (p Portal) Draw(r Renderer) {
+	switch p.status {
+	case "triggered":
+		r.DrawRectangle(p.EntryRectangle(), Blue)
+	case "ended":
+		r.DrawRectangle(p.EntryRectangle(), Blue)
+		r.DrawRectangle(p.ExitRectangle(), Brown)
+	}
+}
+
+func SolvePortalCollision(portal_box *Rectangle, wall Rectangle, direction string) {
+	switch direction {
+	case "bottom":
+		portal_box.Y = wall.Y - PortalHeight
+	case "right":
+		portal_box.X = wall.X + wall.Width
+	case "left":
+		portal_box.X = wall.X - PortalWidth
+	case "top":
+		portal_box.Y = wall.Y + wall.Height
+	}
+}
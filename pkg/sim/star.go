@@ -0,0 +1,68 @@
+package sim
+
+const StarWidth = 32
+const StarHeight = 32
+
+// Hooked stars fly back to the player, HookCollectTarget would collect them
+// on the spot instead
+const StarHookReaction = HookPullTarget
+
+type Star struct {
+	id           EntityID
+	pos, lastPos Vector2
+}
+
+func NewStar(pos Vector2) Star {
+	return Star{pos: pos, lastPos: pos}
+}
+
+func (s Star) Rectangle() Rectangle {
+	return Rectangle{
+		X:      s.pos.X,
+		Y:      s.pos.Y,
+		Width:  StarWidth,
+		Height: StarHeight,
+	}
+}
+
+func (s Star) ID() EntityID {
+	return s.id
+}
+
+func (s Star) HookReaction() HookReaction {
+	return StarHookReaction
+}
+
+func (s *Star) MoveTo(pos Vector2) {
+	s.pos = pos
+}
+
+// pushOutOfWalls moves the star out of the walls it overlaps.
+func (s *Star) pushOutOfWalls(walls []Rectangle) {
+	for _, wall := range walls {
+		if !IsColliding(s.Rectangle(), wall) {
+			continue
+		}
+
+		switch CollisionDirection(s.Rectangle(), wall) {
+		case "bottom":
+			s.pos.Y = wall.Y - StarHeight
+		case "right":
+			s.pos.X = wall.X + wall.Width
+		case "left":
+			s.pos.X = wall.X - StarWidth
+		case "top":
+			s.pos.Y = wall.Y + wall.Height
+		}
+	}
+}
+
+// SaveState keeps the current position as the previous state used to interpolate drawing.
+func (s *Star) SaveState() {
+	s.lastPos = s.pos
+}
+
+func (s Star) Draw(r Renderer, factor float64) {
+	pos := LerpVec2(s.lastPos, s.pos, factor)
+	r.DrawRectangle(Rectangle{X: pos.X, Y: pos.Y, Width: StarWidth, Height: StarHeight}, Yellow)
+}
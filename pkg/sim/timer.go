@@ -0,0 +1,64 @@
+package sim
+
+// Timer measures simulation time. It only moves forward when ticked with the
+// fixed step of the game loop, so it freezes with the simulation and behaves
+// the same in replays and headless runs.
+type Timer struct {
+	duration float64
+	elapsed  float64
+	running  bool
+}
+
+// Steps come as float32 and add up slightly short of round durations
+const timerTolerance = 1e-6
+
+func NewTimer(duration float64) Timer {
+	return Timer{duration: duration}
+}
+
+// Start (re)starts the timer from zero.
+func (t *Timer) Start() {
+	t.elapsed = 0
+	t.running = true
+}
+
+func (t *Timer) Stop() {
+	t.running = false
+}
+
+// Tick advances the timer by dt seconds and returns true on the tick it runs out.
+func (t *Timer) Tick(dt float64) bool {
+	if !t.running {
+		return false
+	}
+
+	t.elapsed += dt
+	if t.elapsed >= t.duration-timerTolerance {
+		t.elapsed = t.duration
+		t.running = false
+		return true
+	}
+
+	return false
+}
+
+func (t Timer) Running() bool {
+	return t.running
+}
+
+func (t Timer) Elapsed() float64 {
+	return t.elapsed
+}
+
+func (t Timer) Remaining() float64 {
+	return t.duration - t.elapsed
+}
+
+// Progress goes from 0 when the timer starts to 1 when it runs out.
+func (t Timer) Progress() float64 {
+	if t.duration <= 0 {
+		return 1
+	}
+
+	return t.elapsed / t.duration
+}
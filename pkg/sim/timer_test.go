@@ -0,0 +1,112 @@
+package sim
+
+import (
+	"testing"
+)
+
+func TestTimerTick(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration float64
+		step     float32
+		ticks    int
+		// Tick that runs the timer out, 0 when it keeps running
+		wantDone int
+	}{
+		{name: "runs out on the last tick", duration: 0.5, step: 0.25, ticks: 3, wantDone: 2},
+		{name: "runs out past its duration", duration: 0.5, step: 0.2, ticks: 4, wantDone: 3},
+		{name: "still running", duration: 1, step: 0.25, ticks: 3, wantDone: 0},
+		// float32(0.01) is a bit under 0.01, ten steps add up short of 0.1
+		{name: "float32 steps", duration: 0.1, step: 0.01, ticks: 12, wantDone: 10},
+		{name: "float32 steps over a second", duration: 1, step: 0.01, ticks: 110, wantDone: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timer := NewTimer(tt.duration)
+			timer.Start()
+
+			done := 0
+			for i := 1; i <= tt.ticks; i++ {
+				if timer.Tick(float64(tt.step)) {
+					if done != 0 {
+						t.Fatalf("timer ran out on tick %v and again on tick %v", done, i)
+					}
+					done = i
+				}
+			}
+
+			if done != tt.wantDone {
+				t.Errorf("timer ran out on tick %v, want %v", done, tt.wantDone)
+			}
+			if timer.Running() != (tt.wantDone == 0) {
+				t.Errorf("timer running = %v after %v ticks", timer.Running(), tt.ticks)
+			}
+			if tt.wantDone != 0 && timer.Remaining() != 0 {
+				t.Errorf("%v left on a timer that ran out", timer.Remaining())
+			}
+		})
+	}
+}
+
+func TestTimerStartStop(t *testing.T) {
+	tests := []struct {
+		name        string
+		run         func(timer *Timer)
+		wantRunning bool
+		wantElapsed float64
+	}{
+		{
+			name:        "never started",
+			run:         func(timer *Timer) { timer.Tick(0.25) },
+			wantRunning: false,
+			wantElapsed: 0,
+		},
+		{
+			name: "stopped",
+			run: func(timer *Timer) {
+				timer.Start()
+				timer.Tick(0.25)
+				timer.Stop()
+				timer.Tick(0.25)
+			},
+			wantRunning: false,
+			wantElapsed: 0.25,
+		},
+		{
+			name: "restarted",
+			run: func(timer *Timer) {
+				timer.Start()
+				timer.Tick(0.5)
+				timer.Start()
+				timer.Tick(0.25)
+			},
+			wantRunning: true,
+			wantElapsed: 0.25,
+		},
+		{
+			name: "restarted after running out",
+			run: func(timer *Timer) {
+				timer.Start()
+				timer.Tick(1)
+				timer.Start()
+			},
+			wantRunning: true,
+			wantElapsed: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timer := NewTimer(1)
+			tt.run(&timer)
+
+			if timer.Running() != tt.wantRunning {
+				t.Errorf("Running() = %v, want %v", timer.Running(), tt.wantRunning)
+			}
+			if timer.Elapsed() != tt.wantElapsed {
+				t.Errorf("Elapsed() = %v, want %v", timer.Elapsed(), tt.wantElapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package sim
+
+var PlayerSpawn = Vector2{X: 32, Y: 32}
+
+// World holds everything that moves or collides in a level. It knows nothing
+// about windows or devices, see backend.go.
+type World struct {
+	Player *Player
+	Stars  []Star
+	Walls  []Rectangle
+	Width  float32
+	Height float32
+	// Hook mode of the players put on the spawn point
+	HookMode HookMode
+	input    Input
+	lastID   EntityID
+}
+
+func NewWorld(mc MapConfiguration, input Input) *World {
+	w := &World{
+		Walls:    mc.Walls(),
+		Width:    float32(mc.Width * mc.TileWidth),
+		Height:   float32(mc.Height * mc.TileHeight),
+		HookMode: HookPull,
+		input:    input,
+	}
+
+	w.Reset()
+	return w
+}
+
+// Reset puts a fresh player on the spawn point and removes every star.
+func (w *World) Reset() {
+	w.Player = NewPlayer(PlayerSpawn, w.input)
+	w.Player.SetHookMode(w.HookMode)
+	w.Stars = nil
+}
+
+// SpawnStar adds a star at the given position unless it overlaps a wall or
+// another star.
+func (w *World) SpawnStar(pos Vector2) bool {
+	star := NewStar(pos)
+	star.id = w.lastID + 1
+
+	for _, wall := range w.Walls {
+		if IsColliding(star.Rectangle(), wall) {
+			return false
+		}
+	}
+
+	for _, exStar := range w.Stars {
+		if IsColliding(star.Rectangle(), exStar.Rectangle()) {
+			return false
+		}
+	}
+
+	w.Stars = append(w.Stars, star)
+	w.lastID = star.id
+	return true
+}
+
+// hookTargets lists the entities the hook can catch.
+func (w *World) hookTargets() []HookTarget {
+	targets := make([]HookTarget, 0, len(w.Stars))
+	for i := range w.Stars {
+		targets = append(targets, &w.Stars[i])
+	}
+
+	return targets
+}
+
+// Step advances the world by deltaTime seconds and returns the stars the
+// player collected during that step.
+func (w *World) Step(deltaTime float32) []Star {
+	w.Player.color = Green
+	w.Player.SaveState()
+	for i := range w.Stars {
+		w.Stars[i].SaveState()
+	}
+
+	w.Player.Update(deltaTime)
+	w.Player.checkAndHandleCollisions(w.Walls)
+
+	targets := w.hookTargets()
+	caught := w.Player.catchWithHook(targets)
+	w.Player.followHookTarget(targets)
+
+	// Stars pulled by the hook go through walls, once let go they stay where
+	// they are, out of the walls
+	for i := range w.Stars {
+		if w.Stars[i].id != w.Player.hook.target {
+			w.Stars[i].pushOutOfWalls(w.Walls)
+		}
+	}
+
+	var collected []Star
+	remaining := w.Stars[:0]
+	for _, star := range w.Stars {
+		if IsColliding(w.Player.Rectangle(), star.Rectangle()) || star.id == caught {
+			collected = append(collected, star)
+		} else {
+			remaining = append(remaining, star)
+		}
+	}
+	w.Stars = remaining
+
+	return collected
+}
+
+func (w World) Draw(r Renderer, factor float64) {
+	w.Player.Draw(r, factor)
+	for _, star := range w.Stars {
+		star.Draw(r, factor)
+	}
+}
@@ -0,0 +1,131 @@
+package sim
+
+import (
+	"testing"
+)
+
+const testMapPath = "../../assets/map.json"
+const testStep = 0.01
+
+// Steps for a player placed a bit above the floor to come to rest
+const settleSteps = 100
+
+// testWorld is a world on the test map, run headless.
+type testWorld struct {
+	*World
+	input *FixedInput
+}
+
+// newTestWorld loads the test map and lets the player placed at pos come to
+// rest on the floor below.
+func newTestWorld(t *testing.T, pos Vector2) testWorld {
+	t.Helper()
+
+	mc, err := NewMapConfiguration(testMapPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tw := testWorld{input: &FixedInput{}}
+	tw.World = NewWorld(mc, tw.input)
+	tw.Player.pos = pos
+	tw.Player.lastPos = pos
+	tw.run(settleSteps)
+
+	return tw
+}
+
+func (tw testWorld) run(steps int) []Star {
+	return RunHeadless(tw.World, steps, testStep)
+}
+
+func TestPlayerSettlesOnFloor(t *testing.T) {
+	tw := newTestWorld(t, PlayerSpawn)
+
+	p := tw.Player
+	if p.Position() != (Vector2{X: 32, Y: 128}) {
+		t.Errorf("player rests at %v, want on the floor at 32, 128", p.Position())
+	}
+	if p.Velocity().Y != 0 {
+		t.Errorf("player still falls at %v", p.Velocity().Y)
+	}
+	if p.State() != StateIdle {
+		t.Errorf("player is %v, want idle", p.State())
+	}
+}
+
+func TestSpawnStar(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []Vector2
+		pos      Vector2
+		want     bool
+	}{
+		{name: "free spot", pos: Vector2{X: 400, Y: 64}, want: true},
+		{name: "inside a wall", pos: Vector2{X: 0, Y: 0}, want: false},
+		{name: "over another star", existing: []Vector2{{X: 400, Y: 64}}, pos: Vector2{X: 410, Y: 70}, want: false},
+		{name: "next to another star", existing: []Vector2{{X: 400, Y: 64}}, pos: Vector2{X: 440, Y: 64}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, PlayerSpawn)
+			for _, pos := range tt.existing {
+				tw.SpawnStar(pos)
+			}
+
+			if got := tw.SpawnStar(tt.pos); got != tt.want {
+				t.Errorf("SpawnStar(%v) = %v, want %v", tt.pos, got, tt.want)
+			}
+			if tt.want && len(tw.Stars) != len(tt.existing)+1 {
+				t.Errorf("%v stars in the world, want %v", len(tw.Stars), len(tt.existing)+1)
+			}
+		})
+	}
+}
+
+func TestStepCollectsTouchedStars(t *testing.T) {
+	tests := []struct {
+		name string
+		star Vector2
+		want int
+	}{
+		{name: "touching the player", star: Vector2{X: 48, Y: 150}, want: 1},
+		{name: "away from the player", star: Vector2{X: 400, Y: 64}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tw := newTestWorld(t, PlayerSpawn)
+			tw.SpawnStar(tt.star)
+
+			collected := tw.run(1)
+			if len(collected) != tt.want || len(tw.Stars) != 1-tt.want {
+				t.Errorf("collected %v stars with %v left, want %v collected", len(collected), len(tw.Stars), tt.want)
+			}
+		})
+	}
+}
+
+// Bound of stepUntil, long enough for any jump or fall of the test map
+const maxTestSteps = 500
+
+// stepUntil runs steps until done reports true and returns how many it took.
+// each is called before every step, to hold a direction for instance.
+func (tw testWorld) stepUntil(t *testing.T, each func(), done func() bool) int {
+	t.Helper()
+
+	for step := 1; step <= maxTestSteps; step++ {
+		if each != nil {
+			each()
+		}
+		tw.run(1)
+
+		if done() {
+			return step
+		}
+	}
+
+	t.Fatalf("still waiting after %v steps", maxTestSteps)
+	return 0
+}